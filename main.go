@@ -18,4 +18,6 @@ func main() {
 		ProviderFunc: postgresql.Provider,
 		ProviderAddr: "registry.terraform.io/Riskified/postgresql",
 		Debug:        debug})
+
+	postgresql.Shutdown()
 }
@@ -1,15 +1,22 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"net"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/blang/semver"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	_ "github.com/lib/pq" // PostgreSQL db
 )
 
@@ -26,12 +33,23 @@ const (
 	featureTransactionIsolation
 	featureSysPrivileges
 	featureFollowerReads
+	featureRoleControlJob
+	featureRoleControlChangefeed
+	featureRoleViewActivity
+	featureRoleCancelQuery
+	featureRoleModifyClusterSetting
+	featureRoleCreateLogin
+	featureRoleNoSQLLogin
+	featureRoleReplication
+	featureRoleSubject
 )
 
 var (
 	dbRegistryLock sync.Mutex
 	dbRegistry     map[string]*DBConnection = make(map[string]*DBConnection, 1)
 
+	warningsLock sync.Mutex
+
 	// Mapping of feature flags to CockroachDB versions
 	featureSupportedCockroachdb = map[featureName]semver.Range{
 		featureRLS:                    semver.MustParseRange(">=25.3.0"),
@@ -44,6 +62,16 @@ var (
 		featureTransactionIsolation:   semver.MustParseRange(">=23.2.0"),
 		featureSysPrivileges:          semver.MustParseRange(">=22.2.0"),
 		featureFollowerReads:          semver.MustParseRange(">=22.2.0"),
+		// CockroachDB-specific role options
+		featureRoleControlJob:           semver.MustParseRange(">=20.2.0"),
+		featureRoleControlChangefeed:    semver.MustParseRange(">=20.2.0"),
+		featureRoleViewActivity:         semver.MustParseRange(">=20.2.0"),
+		featureRoleCancelQuery:          semver.MustParseRange(">=20.2.0"),
+		featureRoleModifyClusterSetting: semver.MustParseRange(">=20.2.0"),
+		featureRoleCreateLogin:          semver.MustParseRange(">=20.2.0"),
+		featureRoleNoSQLLogin:           semver.MustParseRange(">=21.1.0"),
+		featureRoleReplication:          semver.MustParseRange(">=23.1.0"),
+		featureRoleSubject:              semver.MustParseRange(">=23.1.0"),
 	}
 )
 
@@ -55,6 +83,59 @@ type DBConnection struct {
 	// version is the version number of the database as determined by parsing the
 	// output of `SELECT VERSION()`.
 	version semver.Version
+
+	// ctx is the context of the Terraform operation using the connection, statements
+	// are cancelled with it.
+	ctx context.Context
+
+	// warnings collects the warnings returned to Terraform with the result of the operation.
+	warnings *diag.Diagnostics
+}
+
+// withContext returns a copy of the connection bound to the context and warnings of a Terraform operation.
+func (db *DBConnection) withContext(ctx context.Context, warnings *diag.Diagnostics) *DBConnection {
+	conn := *db
+	conn.ctx = ctx
+	conn.warnings = warnings
+	return &conn
+}
+
+// context returns the context statements run with.
+func (db *DBConnection) context() context.Context {
+	if db.ctx == nil {
+		return context.Background()
+	}
+	return db.ctx
+}
+
+// warn reports a warning about the given attribute, or the whole resource when empty, to Terraform.
+func (db *DBConnection) warn(attribute, summary, detail string) {
+	log.Printf("[WARN] %s: %s", summary, detail)
+	if db.warnings == nil {
+		return
+	}
+
+	warning := diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  summary,
+		Detail:   detail,
+	}
+	if attribute != "" {
+		warning.AttributePath = cty.GetAttrPath(attribute)
+	}
+	db.addWarnings(diag.Diagnostics{warning})
+}
+
+// addWarnings reports warnings collected on another connection, e.g. during a transaction attempt.
+func (db *DBConnection) addWarnings(warnings diag.Diagnostics) {
+	if db.warnings == nil || len(warnings) == 0 {
+		return
+	}
+
+	// Warnings may be reported by the concurrent callbacks of forEachDatabase.
+	warningsLock.Lock()
+	defer warningsLock.Unlock()
+	*db.warnings = append(*db.warnings, warnings...)
 }
 
 // featureSupported returns true if a given feature is supported or not. This is
@@ -80,17 +161,29 @@ type ClientCertificateConfig struct {
 type Config struct {
 	Scheme            string
 	Host              string
+	Hosts             []string
+	LoadBalanceHosts  string
 	Port              int
 	Username          string
 	Password          string
+	PasswordFile      string
+	PasswordCommand   *PasswordCommandConfig
 	DatabaseUsername  string
 	SSLMode           string
 	ApplicationName   string
+	VirtualCluster    string
 	Timeout           int
 	ConnectTimeoutSec int
 	MaxConns          int
+	MaxIdleConns      int
+	ConnMaxLifetime   time.Duration
+	ConnMaxIdleTime   time.Duration
+	MaxRetries        int
 	ExpectedVersion   semver.Version
 	SSLClientCert     *ClientCertificateConfig
+	JWT               *JWTConfig
+	Proxy             *ProxyConfig
+	SSHTunnel         *SSHTunnelConfig
 	SSLRootCertPath   string
 }
 
@@ -128,21 +221,75 @@ func (c *Config) connParams() []string {
 		params["sslrootcert"] = c.SSLRootCertPath
 	}
 
+	options := []string{}
+	if c.VirtualCluster != "" {
+		options = append(options, "-ccluster="+c.VirtualCluster)
+	}
+	if c.JWT != nil {
+		options = append(options, jwtAuthOption)
+	}
+	if len(options) > 0 {
+		params["options"] = strings.Join(options, " ")
+	}
+
 	paramsArray := []string{}
 	for key, value := range params {
 		paramsArray = append(paramsArray, fmt.Sprintf("%s=%s", key, url.QueryEscape(value)))
 	}
+	// Sorted so that the connection string of a database, which identifies it in dbRegistry, is stable.
+	sort.Strings(paramsArray)
 
 	return paramsArray
 }
 
+// hostAddresses returns the host:port addresses of the nodes to connect to: the hosts
+// list when set, using port for the hosts without one, or host and port otherwise.
+func (c *Config) hostAddresses() []string {
+	if len(c.Hosts) == 0 {
+		return []string{fmt.Sprintf("%s:%d", c.Host, c.Port)}
+	}
+
+	addresses := make([]string, 0, len(c.Hosts))
+	for _, host := range c.Hosts {
+		if _, _, err := net.SplitHostPort(host); err == nil {
+			addresses = append(addresses, host)
+		} else {
+			addresses = append(addresses, net.JoinHostPort(host, strconv.Itoa(c.Port)))
+		}
+	}
+	return addresses
+}
+
+// connStr returns the connection string of the database, listing every host as libpq does.
 func (c *Config) connStr(database string) string {
+	return c.hostConnStr(strings.Join(c.hostAddresses(), ","), database, c.Password)
+}
+
+// registryKey identifies the connections to the database in dbRegistry: its connection
+// string and the proxy or SSH bastion it is reached through.
+func (c *Config) registryKey(database string) string {
+	key := c.connStr(database)
+	if c.Proxy != nil {
+		key += fmt.Sprintf(" proxy=%s@%s no_proxy=%s", c.Proxy.Username, c.Proxy.URL, c.Proxy.NoProxy)
+	}
+	if c.SSHTunnel != nil {
+		key += fmt.Sprintf(" ssh_tunnel=%s@%s", c.SSHTunnel.User, c.SSHTunnel.address())
+	}
+	return key
+}
+
+// driver returns the driver dialing the connections of the configuration.
+func (c *Config) driver() proxyDriver {
+	return proxyDriver{proxy: c.Proxy, sshTunnel: c.SSHTunnel}
+}
+
+// hostConnStr returns the connection string of the database on a single host.
+func (c *Config) hostConnStr(address, database, password string) string {
 	connStr := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?%s",
+		"postgres://%s:%s@%s/%s?%s",
 		url.PathEscape(c.Username),
-		url.PathEscape(c.Password),
-		c.Host,
-		c.Port,
+		url.PathEscape(password),
+		address,
 		database,
 		strings.Join(c.connParams(), "&"),
 	)
@@ -157,59 +304,143 @@ func (c *Config) getDatabaseUsername() string {
 	return c.Username
 }
 
+// connPassword returns the password to open a new connection to the database on the
+// host with: the JWT token when JWT authentication is enabled, the content of the
+// password file, the output of the password command, the password, or else the
+// password of the matching .pgpass line.
+func (c *Config) connPassword(ctx context.Context, address, database string) (string, error) {
+	switch {
+	case c.JWT != nil:
+		return c.JWT.token(ctx)
+	case c.PasswordFile != "":
+		return readSecretFile("password", c.PasswordFile)
+	case c.PasswordCommand != nil:
+		return c.PasswordCommand.password(ctx)
+	case c.Password != "":
+		return c.Password, nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	return pgpassLookup(pgpassFile(), host, port, database, c.Username)
+}
+
+// invalidatePassword drops the cached JWT token or output of the password command after
+// the server refused it, e.g. because the password was rotated before the end of its TTL.
+func (c *Config) invalidatePassword() {
+	if c.JWT != nil {
+		c.JWT.invalidate()
+	}
+	if c.PasswordCommand != nil {
+		c.PasswordCommand.invalidate()
+	}
+}
+
 // Connect returns a copy to an sql.Open()'ed database connection wrapped in a DBConnection struct.
 // Callers must return their database resources. Use of QueryRow() or Exec() is encouraged.
 // Query() must have their rows.Close()'ed.
 func (c *Client) Connect() (*DBConnection, error) {
+	return c.ConnectContext(context.Background())
+}
+
+// ConnectContext is like Connect, but the server is reached and fingerprinted with ctx when the
+// connection isn't in dbRegistry yet. dbRegistryLock is not held meanwhile, so an unreachable
+// server doesn't block the connections to the other databases.
+func (c *Client) ConnectContext(ctx context.Context) (*DBConnection, error) {
+	key := c.config.registryKey(c.databaseName)
+
 	dbRegistryLock.Lock()
-	defer dbRegistryLock.Unlock()
+	conn, found := dbRegistry[key]
+	dbRegistryLock.Unlock()
+	if found {
+		return conn, nil
+	}
 
-	dsn := c.config.connStr(c.databaseName)
-	conn, found := dbRegistry[dsn]
-	if !found {
-		db, err := sql.Open(proxyDriverName, dsn)
+	db := sql.OpenDB(newHostConnector(&c.config, c.databaseName))
 
-		if err == nil {
-			err = db.Ping()
-		}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("Error connecting to CockroachDB server %s: %s", strings.Join(c.config.hostAddresses(), ", "), scrubSecret(err, c.config.Password))
+	}
+
+	// Idle connections are not kept by default: when we connect on a specific database which
+	// might be managed by terraform, it may be dropped in the plan. Its pool is then closed by
+	// closeDatabaseConnections.
+	db.SetMaxIdleConns(c.config.MaxIdleConns)
+	db.SetMaxOpenConns(c.config.MaxConns)
+	db.SetConnMaxLifetime(c.config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(c.config.ConnMaxIdleTime)
+
+	defaultVersion, _ := semver.Parse(defaultExpectedCockroachDBVersion)
+	version := &c.config.ExpectedVersion
+	if defaultVersion.Equals(c.config.ExpectedVersion) {
+		// Version hint not set by user, need to fingerprint
+		var err error
+		version, err = fingerprintCapabilities(ctx, db)
 		if err != nil {
-			errString := strings.Replace(err.Error(), c.config.Password, "XXXX", 2)
-			return nil, fmt.Errorf("Error connecting to CockroachDB server %s: %s", c.config.Host, errString)
+			_ = db.Close()
+			return nil, fmt.Errorf("error detecting capabilities: %w", err)
 		}
+	}
 
-		// We don't want to retain connection
-		// So when we connect on a specific database which might be managed by terraform,
-		// we don't keep opened connection in case of the db has to be dropped in the plan.
-		db.SetMaxIdleConns(0)
-		db.SetMaxOpenConns(c.config.MaxConns)
-
-		defaultVersion, _ := semver.Parse(defaultExpectedCockroachDBVersion)
-		version := &c.config.ExpectedVersion
-		if defaultVersion.Equals(c.config.ExpectedVersion) {
-			// Version hint not set by user, need to fingerprint
-			version, err = fingerprintCapabilities(db)
-			if err != nil {
-				_ = db.Close()
-				return nil, fmt.Errorf("error detecting capabilities: %w", err)
-			}
-		}
+	dbRegistryLock.Lock()
+	defer dbRegistryLock.Unlock()
 
-		conn = &DBConnection{
-			db,
-			c,
-			*version,
-		}
-		dbRegistry[dsn] = conn
+	// Another caller may have connected to the same database meanwhile, its connection is kept.
+	if conn, found := dbRegistry[key]; found {
+		_ = db.Close()
+		return conn, nil
+	}
+
+	conn = &DBConnection{
+		DB:      db,
+		client:  c,
+		version: *version,
 	}
+	dbRegistry[key] = conn
 
 	return conn, nil
 }
 
+// closeDatabaseConnections closes the pool of connections to the database opened with the
+// configuration, if any, and removes it from dbRegistry. It is called once the database
+// is dropped or renamed, as its connections can't be used anymore.
+func (c *Config) closeDatabaseConnections(database string) {
+	dbRegistryLock.Lock()
+	defer dbRegistryLock.Unlock()
+
+	key := c.registryKey(database)
+	conn, found := dbRegistry[key]
+	if !found {
+		return
+	}
+
+	delete(dbRegistry, key)
+	if err := conn.DB.Close(); err != nil {
+		log.Printf("[WARN] could not close the connections to database %s: %v", database, err)
+	}
+}
+
+// closeDBRegistry closes the pools of connections of every provider configuration.
+func closeDBRegistry() {
+	dbRegistryLock.Lock()
+	defer dbRegistryLock.Unlock()
+
+	for key, conn := range dbRegistry {
+		if err := conn.DB.Close(); err != nil {
+			log.Printf("[WARN] could not close the connections to database %s: %v", conn.client.databaseName, err)
+		}
+		delete(dbRegistry, key)
+	}
+}
+
 // fingerprintCapabilities queries CockroachDB to determine the version.
 // This is only run once per Client.
-func fingerprintCapabilities(db *sql.DB) (*semver.Version, error) {
+func fingerprintCapabilities(ctx context.Context, db *sql.DB) (*semver.Version, error) {
 	var pgVersion string
-	err := db.QueryRow(`SELECT VERSION()`).Scan(&pgVersion)
+	err := db.QueryRowContext(ctx, `SELECT VERSION()`).Scan(&pgVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error querying CockroachDB version: %w", err)
 	}
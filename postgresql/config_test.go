@@ -1,6 +1,8 @@
 package postgresql
 
 import (
+	"context"
+	"database/sql"
 	"reflect"
 	"sort"
 	"strings"
@@ -16,6 +18,9 @@ func TestConfigConnParams(t *testing.T) {
 		{&Config{Scheme: "postgres", SSLMode: "disable"}, []string{"connect_timeout=0", "sslmode=disable"}},
 		{&Config{SSLClientCert: &ClientCertificateConfig{CertificatePath: "/path/to/public-certificate.pem", KeyPath: "/path/to/private-key.pem"}}, []string{"connect_timeout=0", "sslcert=%2Fpath%2Fto%2Fpublic-certificate.pem", "sslkey=%2Fpath%2Fto%2Fprivate-key.pem", "sslmode="}},
 		{&Config{SSLRootCertPath: "/path/to/root.pem"}, []string{"connect_timeout=0", "sslmode=", "sslrootcert=%2Fpath%2Fto%2Froot.pem"}},
+		{&Config{JWT: &JWTConfig{Token: "token"}}, []string{"connect_timeout=0", "options=--crdb%3Ajwt_auth_enabled%3Dtrue", "sslmode="}},
+		{&Config{VirtualCluster: "application"}, []string{"connect_timeout=0", "options=-ccluster%3Dapplication", "sslmode="}},
+		{&Config{VirtualCluster: "tenant-2", JWT: &JWTConfig{Token: "token"}}, []string{"connect_timeout=0", "options=-ccluster%3Dtenant-2+--crdb%3Ajwt_auth_enabled%3Dtrue", "sslmode="}},
 	}
 
 	for _, test := range tests {
@@ -63,3 +68,98 @@ func TestConfigConnStr(t *testing.T) {
 
 	}
 }
+
+func TestConfigHostAddresses(t *testing.T) {
+	var tests = []struct {
+		input *Config
+		want  []string
+	}{
+		{&Config{Host: "localhost", Port: 26257}, []string{"localhost:26257"}},
+		{&Config{Host: "localhost", Hosts: []string{"node1", "node2:26258", "::1", "[::1]:26259"}, Port: 26257}, []string{"node1:26257", "node2:26258", "[::1]:26257", "[::1]:26259"}},
+	}
+
+	for _, test := range tests {
+		if addresses := test.input.hostAddresses(); !reflect.DeepEqual(addresses, test.want) {
+			t.Errorf("Config.hostAddresses(%+v) returned %#v, want %#v", test.input, addresses, test.want)
+		}
+	}
+
+	config := &Config{Hosts: []string{"node1", "node2"}, Port: 26257, Username: "root", SSLMode: "disable"}
+	if connStr := strings.Split(config.connStr("postgres"), "?")[0]; connStr != "postgres://root:@node1:26257,node2:26257/postgres" {
+		t.Errorf("Config.connStr(%+v) returned %#v", config, connStr)
+	}
+}
+
+func TestConfigConnStrVirtualCluster(t *testing.T) {
+	config := &Config{Host: "localhost", Port: 26257, Username: "root", SSLMode: "disable"}
+	system := config.connStr("postgres")
+	if system != config.connStr("postgres") {
+		t.Fatalf("Config.connStr(%+v) is not stable", config)
+	}
+
+	config.VirtualCluster = "application"
+	if tenant := config.connStr("postgres"); tenant == system {
+		t.Fatalf("Config.connStr(%+v) must differ between virtual clusters: %#v", config, tenant)
+	}
+}
+
+func TestConfigCloseDatabaseConnections(t *testing.T) {
+	config := &Config{Host: "localhost", Port: 26257, Username: "root", SSLMode: "disable"}
+	register := func(database string) *DBConnection {
+		conn := &DBConnection{
+			DB:     sql.OpenDB(newHostConnector(config, database)),
+			client: config.NewClient(database),
+		}
+		dbRegistryLock.Lock()
+		dbRegistry[config.registryKey(database)] = conn
+		dbRegistryLock.Unlock()
+		return conn
+	}
+
+	dropped := register("dropped")
+	kept := register("kept")
+
+	config.closeDatabaseConnections("dropped")
+	config.closeDatabaseConnections("missing")
+
+	dbRegistryLock.Lock()
+	_, droppedFound := dbRegistry[config.registryKey("dropped")]
+	_, keptFound := dbRegistry[config.registryKey("kept")]
+	dbRegistryLock.Unlock()
+	if droppedFound || !keptFound {
+		t.Fatalf("expected only the dropped database to be removed from the registry")
+	}
+	if err := dropped.DB.Ping(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Fatalf("expected the connections to the dropped database to be closed, got %v", err)
+	}
+
+	closeDBRegistry()
+	dbRegistryLock.Lock()
+	remaining := len(dbRegistry)
+	dbRegistryLock.Unlock()
+	if remaining != 0 {
+		t.Fatalf("expected the registry to be empty, %d databases remain", remaining)
+	}
+	if err := kept.DB.Ping(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Fatalf("expected every connection to be closed, got %v", err)
+	}
+}
+
+func TestClientConnectContextCancelled(t *testing.T) {
+	config := &Config{Host: "127.0.0.1", Port: 1, Username: "root", SSLMode: "disable"}
+	client := config.NewClient("cancelled")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.ConnectContext(ctx); err == nil {
+		t.Fatalf("expected connecting with a cancelled context to fail")
+	}
+
+	dbRegistryLock.Lock()
+	_, found := dbRegistry[config.registryKey("cancelled")]
+	dbRegistryLock.Unlock()
+	if found {
+		t.Fatalf("expected the failed connection not to be registered")
+	}
+}
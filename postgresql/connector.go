@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+
+	"github.com/lib/pq"
+)
+
+const (
+	loadBalanceHostsDisable = "disable"
+	loadBalanceHostsRandom  = "random"
+
+	// pqErrorCodeInvalidPassword is the SQLSTATE code of authentication failures.
+	pqErrorCodeInvalidPassword = "28P01"
+)
+
+// hostConnector is a driver.Connector opening each connection on the first reachable
+// host of the provider configuration. As database/sql calls it for every new connection,
+// a node going away is skipped by the next statement.
+type hostConnector struct {
+	config    *Config
+	database  string
+	addresses []string
+	random    bool
+}
+
+func newHostConnector(c *Config, database string) *hostConnector {
+	return &hostConnector{
+		config:    c,
+		database:  database,
+		addresses: c.hostAddresses(),
+		random:    c.LoadBalanceHosts == loadBalanceHostsRandom,
+	}
+}
+
+// Connect tries the hosts in order, or in a random order when load balancing is enabled,
+// until one accepts the connection. Errors returned by the server itself (e.g. an
+// authentication failure) are returned immediately as every node would answer the same.
+// The password is resolved again for each connection so that expired tokens are refreshed,
+// dropped from the cache when authentication fails, and removed from the errors.
+func (c *hostConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	order := make([]int, len(c.addresses))
+	for i := range order {
+		order[i] = i
+	}
+	if c.random {
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+
+	var errs []error
+	for _, i := range order {
+		password, err := c.config.connPassword(ctx, c.addresses[i], c.database)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := c.connectHost(ctx, c.config.hostConnStr(c.addresses[i], c.database, password))
+		if err == nil {
+			log.Printf("[DEBUG] opened connection to CockroachDB node %s", c.addresses[i])
+			return conn, nil
+		}
+
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) || ctx.Err() != nil {
+			if pqErr != nil && pqErr.Code == pqErrorCodeInvalidPassword {
+				c.config.invalidatePassword()
+			}
+			return nil, scrubSecret(err, password)
+		}
+		err = scrubSecret(err, password)
+
+		log.Printf("[WARN] could not connect to CockroachDB node %s: %v", c.addresses[i], err)
+		errs = append(errs, fmt.Errorf("%s: %w", c.addresses[i], err))
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+func (c *hostConnector) connectHost(ctx context.Context, dsn string) (driver.Conn, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(c.config.driver())
+	return connector.Connect(ctx)
+}
+
+func (c *hostConnector) Driver() driver.Driver {
+	return c.config.driver()
+}
@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestHostConnectorFailover(t *testing.T) {
+	// Reserve two local ports which refuse connections.
+	var hosts []string
+	for i := 0; i < 2; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("could not listen: %v", err)
+		}
+		hosts = append(hosts, listener.Addr().String())
+		listener.Close()
+	}
+
+	for _, loadBalanceHosts := range []string{loadBalanceHostsDisable, loadBalanceHostsRandom} {
+		config := &Config{Hosts: hosts, LoadBalanceHosts: loadBalanceHosts, Username: "root", SSLMode: "disable", ConnectTimeoutSec: 1}
+		connector := newHostConnector(config, "postgres")
+
+		_, err := connector.Connect(context.Background())
+		if err == nil {
+			t.Fatalf("expected an error connecting to %v", hosts)
+		}
+		for _, host := range hosts {
+			if !strings.Contains(err.Error(), host) {
+				t.Errorf("expected error to mention every tried host, %s is missing: %v", host, err)
+			}
+		}
+	}
+}
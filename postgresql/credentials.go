@@ -0,0 +1,202 @@
+package postgresql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPasswordCommandTTL = 5 * time.Minute
+
+	// secretCommandTimeout bounds the run of a password or token command, which holds
+	// the lock of its cache and so delays every connection being opened.
+	secretCommandTimeout = 30 * time.Second
+)
+
+// PasswordCommandConfig - command printing the password, whose output is cached for TTL.
+type PasswordCommandConfig struct {
+	Command []string
+	TTL     time.Duration
+
+	lock      sync.Mutex
+	cached    string
+	expiresAt time.Time
+}
+
+func (p *PasswordCommandConfig) password(ctx context.Context) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cached != "" && time.Now().Before(p.expiresAt) {
+		return p.cached, nil
+	}
+
+	password, err := runSecretCommand(ctx, "password", p.Command)
+	if err != nil {
+		return "", err
+	}
+
+	p.cached = password
+	p.expiresAt = time.Now().Add(p.TTL)
+	return password, nil
+}
+
+// invalidate drops the cached password, so that the command is run again for the next connection.
+func (p *PasswordCommandConfig) invalidate() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.cached = ""
+}
+
+// runSecretCommand runs a credential helper and returns its trimmed standard output.
+// The command is killed when ctx is done or after secretCommandTimeout.
+func runSecretCommand(ctx context.Context, kind string, command []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, secretCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stderr = &stderr
+	// Don't wait for children of the killed command which still hold its output.
+	cmd.WaitDelay = time.Second
+	output, err := cmd.Output()
+	secret := strings.TrimSpace(string(output))
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		err = ctxErr
+	}
+	if err != nil {
+		return "", scrubSecret(fmt.Errorf("could not run %s command %s: %w: %s", kind, command[0], err, strings.TrimSpace(stderr.String())), secret)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("%s command %s returned an empty %s", kind, command[0], kind)
+	}
+	return secret, nil
+}
+
+// readSecretFile returns the trimmed content of a file holding a secret.
+func readSecretFile(kind, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s file: %w", kind, err)
+	}
+
+	secret := strings.TrimSpace(string(content))
+	if secret == "" {
+		return "", fmt.Errorf("%s file %s is empty", kind, path)
+	}
+	return secret, nil
+}
+
+// pgpassFile returns the path of the password file, as libpq finds it.
+func pgpassFile() string {
+	if path := os.Getenv("PGPASSFILE"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pgpass")
+}
+
+// pgpassLookup returns the password of the first line of a .pgpass file matching the
+// connection, or an empty string if there is none. As with libpq, the file is ignored
+// if it is readable by group or others.
+func pgpassLookup(path, host, port, database, username string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("could not open password file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("could not open password file: %w", err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		log.Printf("[WARN] password file %s has group or world access; permissions should be u=rw (0600) or less", path)
+		return "", nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitPgpassLine(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		matches := true
+		for i, value := range []string{host, port, database, username} {
+			if fields[i] != "*" && fields[i] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return fields[4], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// splitPgpassLine splits a .pgpass line on the colons which are not escaped by a backslash.
+func splitPgpassLine(line string) []string {
+	var fields []string
+	var field strings.Builder
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			field.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(c)
+		}
+	}
+	return append(fields, field.String())
+}
+
+// secretError is an error whose message had a secret removed.
+type secretError struct {
+	message string
+}
+
+func (e *secretError) Error() string {
+	return e.message
+}
+
+// scrubSecret replaces the secret in the message of err. The original error is not
+// wrapped so that the secret can't be recovered from it.
+func scrubSecret(err error, secret string) error {
+	if err == nil || secret == "" || !strings.Contains(err.Error(), secret) {
+		return err
+	}
+	return &secretError{message: strings.ReplaceAll(err.Error(), secret, "XXXX")}
+}
@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitPgpassLine(t *testing.T) {
+	fields := splitPgpassLine(`db.example.com:26257:*:user\:name:pass\\word\:1`)
+	expected := []string{"db.example.com", "26257", "*", "user:name", `pass\word:1`}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", fields, expected)
+	}
+}
+
+func TestPgpassLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pgpass")
+	content := "# comment\nother:26257:*:user:other-password\ndb.example.com:26257:app:user:app-password\n*:*:*:user:default-password\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("could not write password file: %v", err)
+	}
+
+	var tests = []struct {
+		host, database, username string
+		expected                 string
+	}{
+		{"db.example.com", "app", "user", "app-password"},
+		{"db.example.com", "postgres", "user", "default-password"},
+		{"db.example.com", "app", "admin", ""},
+	}
+	for _, test := range tests {
+		password, err := pgpassLookup(path, test.host, "26257", test.database, test.username)
+		if err != nil {
+			t.Fatalf("could not look up password: %v", err)
+		}
+		if password != test.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", password, test.expected)
+		}
+	}
+
+	if password, err := pgpassLookup(filepath.Join(t.TempDir(), "missing"), "db.example.com", "26257", "app", "user"); err != nil || password != "" {
+		t.Fatalf("expected no password for a missing file, got %#v (%v)", password, err)
+	}
+
+	// Files readable by others are ignored.
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatalf("could not change password file permissions: %v", err)
+	}
+	if password, err := pgpassLookup(path, "db.example.com", "26257", "app", "user"); err != nil || password != "" {
+		t.Fatalf("expected no password for a world-readable file, got %#v (%v)", password, err)
+	}
+}
+
+func TestPasswordCommandConfig(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	writePassword := func(password string) {
+		if err := os.WriteFile(passwordFile, []byte(password+"\n"), 0600); err != nil {
+			t.Fatalf("could not write password file: %v", err)
+		}
+	}
+
+	config := &PasswordCommandConfig{Command: []string{"cat", passwordFile}, TTL: time.Hour}
+	writePassword("first")
+	if password, err := config.password(context.Background()); err != nil || password != "first" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", password, "first", err)
+	}
+	writePassword("second")
+	if password, err := config.password(context.Background()); err != nil || password != "first" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", password, "first", err)
+	}
+
+	config = &PasswordCommandConfig{Command: []string{"cat", passwordFile}}
+	if password, err := config.password(context.Background()); err != nil || password != "second" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", password, "second", err)
+	}
+
+	// The cache is dropped when the password is refused.
+	config = &PasswordCommandConfig{Command: []string{"cat", passwordFile}, TTL: time.Hour}
+	if _, err := config.password(context.Background()); err != nil {
+		t.Fatalf("could not run password command: %v", err)
+	}
+	writePassword("rotated")
+	(&Config{PasswordCommand: config}).invalidatePassword()
+	if password, err := config.password(context.Background()); err != nil || password != "rotated" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", password, "rotated", err)
+	}
+
+	if _, err := (&PasswordCommandConfig{Command: []string{"false"}}).password(context.Background()); err == nil {
+		t.Fatal("expected an error for a failing command")
+	}
+
+	// Commands are killed with the connection being opened.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := (&PasswordCommandConfig{Command: []string{"sleep", "10"}}).password(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 5*time.Second {
+		t.Fatalf("expected the password command to time out, got %v after %s", err, time.Since(start))
+	}
+}
+
+func TestConfigConnPassword(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("could not write password file: %v", err)
+	}
+	pgpass := filepath.Join(t.TempDir(), "pgpass")
+	if err := os.WriteFile(pgpass, []byte("db.example.com:26257:app:user:from-pgpass\n"), 0600); err != nil {
+		t.Fatalf("could not write password file: %v", err)
+	}
+	t.Setenv("PGPASSFILE", pgpass)
+
+	var tests = []struct {
+		config   *Config
+		expected string
+	}{
+		{&Config{Username: "user", Password: "literal", PasswordFile: passwordFile}, "from-file"},
+		{&Config{Username: "user", PasswordCommand: &PasswordCommandConfig{Command: []string{"echo", "from-command"}}}, "from-command"},
+		{&Config{Username: "user", Password: "literal"}, "literal"},
+		{&Config{Username: "user"}, "from-pgpass"},
+	}
+	for _, test := range tests {
+		password, err := test.config.connPassword(context.Background(), "db.example.com:26257", "app")
+		if err != nil {
+			t.Fatalf("could not resolve password: %v", err)
+		}
+		if password != test.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", password, test.expected)
+		}
+	}
+}
+
+func TestScrubSecret(t *testing.T) {
+	err := scrubSecret(fmt.Errorf("authentication failed for s3cr3t: %w", errors.New("s3cr3t rejected")), "s3cr3t")
+	if err.Error() != "authentication failed for XXXX: XXXX rejected" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", err.Error(), "authentication failed for XXXX: XXXX rejected")
+	}
+	if errors.Unwrap(err) != nil {
+		t.Fatal("scrubbed errors must not wrap the original error")
+	}
+
+	original := errors.New("connection refused")
+	if scrubSecret(original, "s3cr3t") != original || scrubSecret(original, "") != original {
+		t.Fatal("errors without the secret must be returned unchanged")
+	}
+}
@@ -0,0 +1,272 @@
+package postgresql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	// effectivePrivilegesQuery lists the schema, table, sequence and function privileges
+	// of the given grantees in the current database, excluding the system schemas.
+	effectivePrivilegesQuery = `
+	SELECT table_schema, '', 'schema', privilege_type, '', grantee
+	FROM information_schema.schema_privileges
+	WHERE grantee = ANY($1) AND table_schema NOT IN ('pg_catalog', 'information_schema', 'crdb_internal', 'pg_extension')
+	UNION ALL
+	SELECT p.table_schema, p.table_name, CASE WHEN t.table_type = 'SEQUENCE' THEN 'sequence' ELSE 'table' END,
+		p.privilege_type, COALESCE(p.grantor, ''), p.grantee
+	FROM information_schema.table_privileges p
+	JOIN information_schema.tables t ON t.table_schema = p.table_schema AND t.table_name = p.table_name
+	WHERE p.grantee = ANY($1) AND p.table_schema NOT IN ('pg_catalog', 'information_schema', 'crdb_internal', 'pg_extension')
+	UNION ALL
+	SELECT routine_schema, routine_name, 'function', privilege_type, COALESCE(grantor, ''), grantee
+	FROM information_schema.role_routine_grants
+	WHERE grantee = ANY($1) AND routine_schema NOT IN ('pg_catalog', 'information_schema', 'crdb_internal', 'pg_extension')
+	`
+)
+
+func dataSourcePostgreSQLEffectivePrivileges() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLEffectivePrivilegesRead),
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role whose effective privileges are returned",
+			},
+			"databases": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "The databases which will be queried for privileges. Queries all non-system databases by default",
+			},
+			"include_inherited": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Include the privileges inherited from the roles the role is a member of, directly or indirectly",
+			},
+			"include_public": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Include the privileges granted to the public role",
+			},
+			"privileges": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"database": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"schema": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"object": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"object_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"privilege": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"grantor": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"inherited_from": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+				Description: "The effective privileges of the role. `inherited_from` is empty for privileges granted directly to the role",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLEffectivePrivilegesRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get("role").(string)
+
+	grantees, err := getEffectiveGrantees(db, d, role)
+	if err != nil {
+		return err
+	}
+
+	privileges := make([]interface{}, 0)
+
+	if db.featureSupported(featureSysPrivileges) {
+		systemPrivileges, err := readEffectiveSystemPrivileges(db, role, grantees)
+		if err != nil {
+			return err
+		}
+		privileges = append(privileges, systemPrivileges...)
+	}
+
+	databases := make([]string, 0)
+	for _, database := range d.Get("databases").([]interface{}) {
+		databases = append(databases, database.(string))
+	}
+	if len(databases) == 0 {
+		if databases, err = getDatabases(db); err != nil {
+			return err
+		}
+	}
+	sort.Strings(databases)
+
+	for _, database := range databases {
+		dbConn, err := connectToDatabase(db, database)
+		if err != nil {
+			return err
+		}
+
+		databasePrivileges, err := readEffectiveDatabasePrivileges(dbConn, database, role, grantees)
+		if err != nil {
+			return err
+		}
+		privileges = append(privileges, databasePrivileges...)
+	}
+
+	d.Set("privileges", privileges)
+	d.SetId(generateDataSourceEffectivePrivilegesID(d, role))
+
+	return nil
+}
+
+// getEffectiveGrantees returns the role itself followed by the roles it
+// inherits privileges from, according to the data source settings.
+func getEffectiveGrantees(db QueryAble, d *schema.ResourceData, role string) ([]string, error) {
+	grantees := []string{role}
+
+	if d.Get("include_inherited").(bool) {
+		inheritedRoles, err := getInheritedRoles(db, role)
+		if err != nil {
+			return nil, err
+		}
+		grantees = append(grantees, inheritedRoles...)
+	}
+
+	if d.Get("include_public").(bool) && role != publicRole {
+		grantees = append(grantees, publicRole)
+	}
+
+	return grantees, nil
+}
+
+func readEffectiveSystemPrivileges(db QueryAble, role string, grantees []string) ([]interface{}, error) {
+	privileges := make([]interface{}, 0)
+
+	for _, grantee := range grantees {
+		if grantee == publicRole {
+			continue
+		}
+
+		rows, err := db.Query(fmt.Sprintf(
+			"WITH a AS (SHOW SYSTEM GRANTS FOR %s) SELECT privilege_type FROM a ORDER BY privilege_type",
+			pq.QuoteIdentifier(grantee),
+		))
+		if err != nil {
+			return nil, fmt.Errorf("could not read system privileges of role %s: %w", grantee, err)
+		}
+
+		for rows.Next() {
+			var privilege string
+			if err := rows.Scan(&privilege); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("could not scan system privilege: %w", err)
+			}
+			privileges = append(privileges, effectivePrivilege("", "", "", "system", privilege, "", role, grantee))
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return privileges, nil
+}
+
+func readEffectiveDatabasePrivileges(db QueryAble, database, role string, grantees []string) ([]interface{}, error) {
+	privileges := make([]interface{}, 0)
+
+	rows, err := db.Query(fmt.Sprintf(
+		"WITH a AS (SHOW GRANTS ON DATABASE %s) SELECT privilege_type, grantee FROM a WHERE grantee = ANY($1) ORDER BY grantee, privilege_type",
+		pq.QuoteIdentifier(database),
+	), pq.Array(grantees))
+	if err != nil {
+		return nil, fmt.Errorf("could not read privileges on database %s: %w", database, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var privilege, grantee string
+		if err := rows.Scan(&privilege, &grantee); err != nil {
+			return nil, fmt.Errorf("could not scan privileges on database %s: %w", database, err)
+		}
+		privileges = append(privileges, effectivePrivilege(database, "", database, "database", privilege, "", role, grantee))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	objectRows, err := db.Query(effectivePrivilegesQuery+" ORDER BY 1, 2, 3, 4, 6", pq.Array(grantees))
+	if err != nil {
+		return nil, fmt.Errorf("could not read privileges in database %s: %w", database, err)
+	}
+	defer objectRows.Close()
+
+	for objectRows.Next() {
+		var schemaName, objectName, objectType, privilege, grantor, grantee string
+		if err := objectRows.Scan(&schemaName, &objectName, &objectType, &privilege, &grantor, &grantee); err != nil {
+			return nil, fmt.Errorf("could not scan privileges in database %s: %w", database, err)
+		}
+		if objectType == "schema" {
+			objectName = schemaName
+		}
+		privileges = append(privileges, effectivePrivilege(database, schemaName, objectName, objectType, privilege, grantor, role, grantee))
+	}
+
+	return privileges, objectRows.Err()
+}
+
+// effectivePrivilege builds a privileges entry, recording the grantee the
+// privilege is inherited from when it isn't the role itself.
+func effectivePrivilege(database, schemaName, object, objectType, privilege, grantor, role, grantee string) map[string]interface{} {
+	inheritedFrom := ""
+	if grantee != role {
+		inheritedFrom = grantee
+	}
+
+	return map[string]interface{}{
+		"database":       database,
+		"schema":         schemaName,
+		"object":         object,
+		"object_type":    objectType,
+		"privilege":      privilege,
+		"grantor":        grantor,
+		"inherited_from": inheritedFrom,
+	}
+}
+
+func generateDataSourceEffectivePrivilegesID(d *schema.ResourceData, role string) string {
+	return strings.Join([]string{
+		role,
+		generatePatternArrayString(d.Get("databases").([]interface{}), queryArrayKeywordAny),
+		fmt.Sprintf("%t", d.Get("include_inherited").(bool)),
+		fmt.Sprintf("%t", d.Get("include_public").(bool)),
+	}, "_")
+}
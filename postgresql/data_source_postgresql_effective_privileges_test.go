@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestEffectivePrivilege(t *testing.T) {
+	direct := effectivePrivilege("db", "s", "t", "table", "SELECT", "", "app", "app")
+	if direct["inherited_from"] != "" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", direct["inherited_from"], "")
+	}
+
+	inherited := effectivePrivilege("db", "s", "t", "table", "SELECT", "", "app", "readers")
+	if inherited["inherited_from"] != "readers" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", inherited["inherited_from"], "readers")
+	}
+}
+
+func TestAccPostgresqlDataSourceEffectivePrivileges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	// The parent role is created first so it is dropped after the test database
+	// which holds its privileges.
+	parentRole := acctest.RandomWithPrefix("test_effective_privileges_parent")
+	defer createTestRole(t, parentRole)()
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("GRANT SELECT ON TABLE test_schema.test_table TO %s", parentRole))
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("GRANT %s TO %s", parentRole, roleName))
+
+	testAccConfig := fmt.Sprintf(`
+	data "postgresql_effective_privileges" "direct" {
+		role              = "%[2]s"
+		databases         = ["%[1]s"]
+		include_inherited = false
+		include_public    = false
+	}
+
+	data "postgresql_effective_privileges" "inherited" {
+		role      = "%[2]s"
+		databases = ["%[1]s"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.postgresql_effective_privileges.direct", "privileges.*", map[string]string{
+						"database":       dbName,
+						"schema":         "test_schema",
+						"object":         "test_schema",
+						"object_type":    "schema",
+						"privilege":      "USAGE",
+						"inherited_from": "",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("data.postgresql_effective_privileges.inherited", "privileges.*", map[string]string{
+						"database":       dbName,
+						"schema":         "test_schema",
+						"object":         "test_table",
+						"object_type":    "table",
+						"privilege":      "SELECT",
+						"inherited_from": parentRole,
+					}),
+				),
+			},
+		},
+	})
+}
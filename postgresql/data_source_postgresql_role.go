@@ -0,0 +1,146 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	roleDataSourceMemberOfAttr = "member_of"
+	roleDataSourceOptionsAttr  = "options"
+)
+
+func dataSourcePostgreSQLRole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLRoleRead),
+		Schema: map[string]*schema.Schema{
+			roleNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the role",
+			},
+			roleLoginAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the role is allowed to log in",
+			},
+			roleCreateDBAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the role can create databases",
+			},
+			roleCreateRoleAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the role can create new roles",
+			},
+			roleBypassRLSAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the role bypasses every row-level security (RLS) policy",
+			},
+			roleValidUntilAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date and time after which the role's password is no longer valid",
+			},
+			roleDataSourceMemberOfAttr: {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The roles the role is a direct member of",
+			},
+			roleDataSourceOptionsAttr: {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The role options listed by SHOW ROLES, keyed by option name. Options without a value (e.g. CONTROLJOB) map to an empty string",
+			},
+			roleSessionDefaultsAttr: {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The session variables set for the role in every database",
+			},
+			roleDatabaseSessionDefaultsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"database": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"settings": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+				Description: "The session variables set for the role in specific databases",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRoleRead(db *DBConnection, d *schema.ResourceData) error {
+	roleName := d.Get(roleNameAttr).(string)
+
+	var roleCanLogin, roleCreateDB, roleCreateRole, roleBypassRLS bool
+	var roleValidUntil string
+	var memberOf pq.ByteaArray
+
+	bypassRLSColumn := "false"
+	if db.featureSupported(featureRLS) {
+		bypassRLSColumn = "rolbypassrls"
+	}
+
+	err := queryRowScan(db, fmt.Sprintf(`SELECT ARRAY(
+			SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid
+		), rolcanlogin, rolcreatedb, rolcreaterole, COALESCE(rolvaliduntil::TEXT, 'infinity'), %s
+		FROM pg_catalog.pg_roles WHERE rolname=$1`, bypassRLSColumn),
+		[]interface{}{roleName},
+		&memberOf, &roleCanLogin, &roleCreateDB, &roleCreateRole, &roleValidUntil, &roleBypassRLS,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("role %s does not exist", roleName)
+	case err != nil:
+		return fmt.Errorf("Error reading ROLE: %w", err)
+	}
+
+	var roleConfig pq.ByteaArray
+	settingSQL := `SELECT setconfig FROM pg_catalog.pg_db_role_setting
+		WHERE setrole = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname=$1) AND setdatabase = 0`
+	if err := queryRowScan(db, settingSQL, []interface{}{roleName}, &roleConfig); err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("Error reading role settings from pg_db_role_setting: %w", err)
+	}
+
+	databaseSessionDefaults, err := readDatabaseSessionDefaults(db, roleName)
+	if err != nil {
+		return err
+	}
+
+	roleOptions, err := readRoleOptions(db, roleName)
+	if err != nil {
+		return err
+	}
+
+	d.Set(roleLoginAttr, roleCanLogin)
+	d.Set(roleCreateDBAttr, roleCreateDB)
+	d.Set(roleCreateRoleAttr, roleCreateRole)
+	d.Set(roleBypassRLSAttr, roleBypassRLS)
+	d.Set(roleValidUntilAttr, normalizeRoleValidUntil(roleValidUntil))
+	d.Set(roleDataSourceMemberOfAttr, pgArrayToSet(memberOf))
+	d.Set(roleDataSourceOptionsAttr, roleOptions)
+	d.Set(roleSessionDefaultsAttr, readSessionDefaults(roleConfig, nil))
+	d.Set(roleDatabaseSessionDefaultsAttr, databaseSessionDefaults)
+	d.SetId(roleName)
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package postgresql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	roleGraphDirectionUp   = "up"
+	roleGraphDirectionDown = "down"
+
+	// roleGraphDepthLimit bounds the recursion when max_depth is not set.
+	roleGraphDepthLimit = 100
+)
+
+// roleMembershipGraphQuery walks pg_auth_members recursively from $1, up to $2 levels deep.
+// Walking up follows the roles the member belongs to, walking down follows the members of the role.
+// Edges reached through several paths are reported once, at their smallest depth.
+func roleMembershipGraphQuery(direction string) string {
+	from, next := "m.member", "e.role_name"
+	if direction == roleGraphDirectionDown {
+		from, next = "m.roleid", "e.member_name"
+	}
+
+	return fmt.Sprintf(`WITH RECURSIVE edges(role_name, member_name, admin_option, depth) AS (
+			SELECT pg_get_userbyid(m.roleid), pg_get_userbyid(m.member), m.admin_option, 1
+			FROM pg_catalog.pg_auth_members m
+			WHERE pg_get_userbyid(%s) = $1
+			UNION ALL
+			SELECT pg_get_userbyid(m.roleid), pg_get_userbyid(m.member), m.admin_option, e.depth + 1
+			FROM pg_catalog.pg_auth_members m
+			JOIN edges e ON pg_get_userbyid(%s) = %s
+			WHERE e.depth < $2
+		)
+		SELECT role_name, member_name, bool_or(admin_option), min(depth)
+		FROM edges
+		GROUP BY role_name, member_name
+		ORDER BY 4, 1, 2`, from, from, next)
+}
+
+func dataSourcePostgreSQLRoleMembershipGraph() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLRoleMembershipGraphRead),
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role the graph is walked from",
+			},
+			"direction": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      roleGraphDirectionUp,
+				ValidateFunc: validation.StringInSlice([]string{roleGraphDirectionUp, roleGraphDirectionDown}, false),
+				Description:  "Walk `up` to the roles the role is a member of, or `down` to the members of the role",
+			},
+			"max_depth": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of membership levels to walk. 0 walks the whole graph",
+			},
+			"edges": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"member": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"admin_option": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"depth": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+				Description: "The memberships reached from the role, ordered by depth. Direct memberships have a depth of 1",
+			},
+			"closure": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The sorted transitive closure of the roles reached from the role, excluding the role itself",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRoleMembershipGraphRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get("role").(string)
+	direction := d.Get("direction").(string)
+
+	maxDepth := d.Get("max_depth").(int)
+	if maxDepth == 0 {
+		maxDepth = roleGraphDepthLimit
+	}
+
+	rows, err := db.Query(roleMembershipGraphQuery(direction), role, maxDepth)
+	if err != nil {
+		return fmt.Errorf("could not read role membership graph of %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	edges := make([]interface{}, 0)
+	reached := make(map[string]bool)
+	for rows.Next() {
+		var roleName, memberName string
+		var adminOption bool
+		var depth int
+		if err := rows.Scan(&roleName, &memberName, &adminOption, &depth); err != nil {
+			return fmt.Errorf("could not scan role membership: %w", err)
+		}
+
+		edges = append(edges, map[string]interface{}{
+			"role":         roleName,
+			"member":       memberName,
+			"admin_option": adminOption,
+			"depth":        depth,
+		})
+
+		if direction == roleGraphDirectionDown {
+			reached[memberName] = true
+		} else {
+			reached[roleName] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	closure := make([]string, 0, len(reached))
+	for roleName := range reached {
+		if roleName != role {
+			closure = append(closure, roleName)
+		}
+	}
+
+	sort.Strings(closure)
+
+	d.Set("edges", edges)
+	d.Set("closure", closure)
+	d.SetId(strings.Join([]string{role, direction, strconv.Itoa(d.Get("max_depth").(int))}, "_"))
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestRoleMembershipGraphQuery(t *testing.T) {
+	up := roleMembershipGraphQuery(roleGraphDirectionUp)
+	if !strings.Contains(up, "WHERE pg_get_userbyid(m.member) = $1") || !strings.Contains(up, "pg_get_userbyid(m.member) = e.role_name") {
+		t.Fatalf("up query does not walk from members to roles: %s", up)
+	}
+
+	down := roleMembershipGraphQuery(roleGraphDirectionDown)
+	if !strings.Contains(down, "WHERE pg_get_userbyid(m.roleid) = $1") || !strings.Contains(down, "pg_get_userbyid(m.roleid) = e.member_name") {
+		t.Fatalf("down query does not walk from roles to members: %s", down)
+	}
+}
+
+func TestAccPostgresqlDataSourceRoleMembershipGraph(t *testing.T) {
+	skipIfNotAcc(t)
+
+	// test_graph_app is a member of test_graph_readers, with admin option,
+	// which is a member of test_graph_base.
+	roles := []string{"test_graph_base", "test_graph_readers", "test_graph_app"}
+	for _, role := range roles {
+		defer createTestRole(t, role)()
+	}
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr("postgres"), "GRANT test_graph_base TO test_graph_readers")
+	dbExecute(t, config.connStr("postgres"), "GRANT test_graph_readers TO test_graph_app WITH ADMIN OPTION")
+
+	testAccConfig := fmt.Sprintf(`
+	data "postgresql_role_membership_graph" "up" {
+		role = "%[2]s"
+	}
+
+	data "postgresql_role_membership_graph" "up_direct" {
+		role      = "%[2]s"
+		max_depth = 1
+	}
+
+	data "postgresql_role_membership_graph" "down" {
+		role      = "%[1]s"
+		direction = "down"
+	}
+	`, roles[0], roles[2])
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.0.role", "test_graph_readers"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.0.member", "test_graph_app"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.0.admin_option", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.0.depth", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.1.role", "test_graph_base"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.1.admin_option", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "edges.1.depth", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "closure.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "closure.0", "test_graph_base"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up", "closure.1", "test_graph_readers"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up_direct", "edges.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.up_direct", "closure.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.down", "edges.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.down", "closure.0", "test_graph_app"),
+					resource.TestCheckResourceAttr("data.postgresql_role_membership_graph.down", "closure.1", "test_graph_readers"),
+				),
+			},
+		},
+	})
+}
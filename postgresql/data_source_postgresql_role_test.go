@@ -0,0 +1,53 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceRole(t *testing.T) {
+	skipIfNotAcc(t)
+
+	parentRole := "test_ds_role_parent"
+	defer createTestRole(t, parentRole)()
+
+	roleName := "test_ds_role"
+	defer createTestRole(t, roleName)()
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT %s TO %s", parentRole, roleName))
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("ALTER ROLE %s CREATEDB CONTROLJOB", roleName))
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("ALTER ROLE %s SET application_name = 'ds_role'", roleName))
+
+	testAccConfig := fmt.Sprintf(`
+	data "postgresql_role" "role" {
+		name = "%s"
+	}
+	`, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRoleControlJob)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "id", roleName),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "login", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "create_database", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "create_role", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "valid_until", "infinity"),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "member_of.#", "1"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_role.role", "member_of.*", parentRole),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "options.CONTROLJOB", ""),
+					resource.TestCheckResourceAttr("data.postgresql_role.role", "session_defaults.application_name", "ds_role"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,242 @@
+package postgresql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+var roleQueries = map[string]string{
+	"query_include_system_roles": `
+	SELECT rolname, rolcanlogin, rolcreatedb, rolcreaterole, COALESCE(rolvaliduntil::TEXT, 'infinity'),
+		ARRAY(SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid)
+	FROM pg_catalog.pg_roles
+	`,
+	"query_exclude_system_roles": `
+	SELECT rolname, rolcanlogin, rolcreatedb, rolcreaterole, COALESCE(rolvaliduntil::TEXT, 'infinity'),
+		ARRAY(SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid)
+	FROM pg_catalog.pg_roles
+	WHERE rolname NOT IN ('root', 'admin', 'node', 'public')
+	AND rolname NOT LIKE 'crdb_internal_%'
+	`,
+}
+
+const rolePatternMatchingTarget = "rolname"
+
+func dataSourcePostgreSQLRoles() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLRolesRead),
+		Schema: map[string]*schema.Schema{
+			"include_system_roles": {
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Determines whether to include the system roles (root, admin, node, public and crdb_internal_ roles)",
+			},
+			"like_any_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "Expression(s) which will be pattern matched against the role name in the query using the PostgreSQL LIKE ANY operator",
+			},
+			"like_all_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "Expression(s) which will be pattern matched against the role name in the query using the PostgreSQL LIKE ALL operator",
+			},
+			"not_like_all_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "Expression(s) which will be pattern matched against the role name in the query using the PostgreSQL NOT LIKE ALL operator",
+			},
+			"regex_pattern": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Expression which will be pattern matched against the role name in the query using the PostgreSQL ~ (regular expression match) operator",
+			},
+			"options": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "Role options (as listed by SHOW ROLES, e.g. NOLOGIN, CREATEDB or CONTROLJOB) which the roles must all have",
+			},
+			"member_of": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "Roles which the roles must all be direct members of",
+			},
+			"roles": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"login": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"create_database": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"create_role": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"valid_until": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"member_of": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"options": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+				Description: "The list of roles retrieved by this data source, ordered by name",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRolesRead(db *DBConnection, d *schema.ResourceData) error {
+	var query string
+	var queryConcatKeyword string
+	if d.Get("include_system_roles").(bool) {
+		query = roleQueries["query_include_system_roles"]
+		queryConcatKeyword = queryConcatKeywordWhere
+	} else {
+		query = roleQueries["query_exclude_system_roles"]
+		queryConcatKeyword = queryConcatKeywordAnd
+	}
+
+	query = applyRoleDataSourceQueryFilters(query, queryConcatKeyword, d) + " ORDER BY rolname"
+
+	allOptions, err := readAllRoleOptions(db)
+	if err != nil {
+		return err
+	}
+
+	requiredOptions := make([]string, 0)
+	for _, option := range d.Get("options").([]interface{}) {
+		requiredOptions = append(requiredOptions, strings.ToUpper(option.(string)))
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	roles := make([]interface{}, 0)
+	for rows.Next() {
+		var roleName, roleValidUntil string
+		var roleCanLogin, roleCreateDB, roleCreateRole bool
+		var memberOf pq.StringArray
+
+		if err := rows.Scan(&roleName, &roleCanLogin, &roleCreateDB, &roleCreateRole, &roleValidUntil, &memberOf); err != nil {
+			return fmt.Errorf("could not scan role: %w", err)
+		}
+
+		roleOptions := allOptions[roleName]
+		if !roleHasOptions(roleOptions, requiredOptions) {
+			continue
+		}
+
+		roles = append(roles, map[string]interface{}{
+			"name":            roleName,
+			"login":           roleCanLogin,
+			"create_database": roleCreateDB,
+			"create_role":     roleCreateRole,
+			"valid_until":     normalizeRoleValidUntil(roleValidUntil),
+			"member_of":       []string(memberOf),
+			"options":         roleOptions,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	d.Set("roles", roles)
+	d.SetId(generateDataSourceRolesID(d))
+
+	return nil
+}
+
+// readAllRoleOptions returns the options of every role listed by SHOW ROLES, keyed by role name.
+func readAllRoleOptions(db QueryAble) (map[string]map[string]string, error) {
+	rows, err := db.Query("WITH a AS (SHOW ROLES) SELECT username, COALESCE(options::STRING, '') FROM a")
+	if err != nil {
+		return nil, fmt.Errorf("could not read role options: %w", err)
+	}
+	defer rows.Close()
+
+	allOptions := make(map[string]map[string]string)
+	for rows.Next() {
+		var roleName, options string
+		if err := rows.Scan(&roleName, &options); err != nil {
+			return nil, fmt.Errorf("could not scan role options: %w", err)
+		}
+		if allOptions[roleName], err = parseRoleOptions(options); err != nil {
+			return nil, err
+		}
+	}
+
+	return allOptions, rows.Err()
+}
+
+// roleHasOptions returns true if every required option is set in the role options.
+func roleHasOptions(roleOptions map[string]string, requiredOptions []string) bool {
+	for _, option := range requiredOptions {
+		if _, ok := roleOptions[option]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func generateDataSourceRolesID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		strconv.FormatBool(d.Get("include_system_roles").(bool)),
+		generatePatternArrayString(d.Get("like_any_patterns").([]interface{}), queryArrayKeywordAny),
+		generatePatternArrayString(d.Get("like_all_patterns").([]interface{}), queryArrayKeywordAll),
+		generatePatternArrayString(d.Get("not_like_all_patterns").([]interface{}), queryArrayKeywordAll),
+		d.Get("regex_pattern").(string),
+		generatePatternArrayString(d.Get("options").([]interface{}), queryArrayKeywordAll),
+		generatePatternArrayString(d.Get("member_of").([]interface{}), queryArrayKeywordAll),
+	}, "_")
+}
+
+func applyRoleDataSourceQueryFilters(query string, queryConcatKeyword string, d *schema.ResourceData) string {
+	filters := []string{}
+	filters = append(filters, applyPatternMatchingToQuery(rolePatternMatchingTarget, d)...)
+
+	for _, memberOf := range d.Get("member_of").([]interface{}) {
+		filters = append(filters, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM pg_catalog.pg_auth_members members WHERE members.member = pg_roles.oid AND pg_get_userbyid(members.roleid) = %s)",
+			pq.QuoteLiteral(memberOf.(string)),
+		))
+	}
+
+	return finalizeQueryWithFilters(query, queryConcatKeyword, filters)
+}
@@ -0,0 +1,105 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestRoleHasOptions(t *testing.T) {
+	roleOptions := map[string]string{"CONTROLJOB": "", "NOLOGIN": ""}
+
+	cases := []struct {
+		required []string
+		expected bool
+	}{
+		{required: nil, expected: true},
+		{required: []string{"CONTROLJOB"}, expected: true},
+		{required: []string{"CONTROLJOB", "NOLOGIN"}, expected: true},
+		{required: []string{"CONTROLJOB", "CREATEDB"}, expected: false},
+	}
+
+	for _, c := range cases {
+		if out := roleHasOptions(roleOptions, c.required); out != c.expected {
+			t.Fatalf("Error matching output and expected for %v: %#v vs %#v", c.required, out, c.expected)
+		}
+	}
+}
+
+func TestAccPostgresqlDataSourceRoles(t *testing.T) {
+	skipIfNotAcc(t)
+
+	parentRole := "test_ds_roles_parent"
+	defer createTestRole(t, parentRole)()
+
+	roles := []string{"test_ds_roles_app1", "test_ds_roles_app2", "test_ds_roles_other"}
+	for _, role := range roles {
+		defer createTestRole(t, role)()
+	}
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT %s TO test_ds_roles_app1, test_ds_roles_other", parentRole))
+	dbExecute(t, config.connStr("postgres"), "ALTER ROLE test_ds_roles_app2 CREATEDB")
+
+	testAccConfig := `
+	data "postgresql_roles" "like_ds_roles" {
+		like_any_patterns = ["test_ds_roles_%"]
+	}
+
+	data "postgresql_roles" "like_app_not_like_1" {
+		like_all_patterns     = ["test_ds_roles_app%"]
+		not_like_all_patterns = ["%1"]
+	}
+
+	data "postgresql_roles" "regex_app" {
+		regex_pattern = "^test_ds_roles_app.*$"
+	}
+
+	data "postgresql_roles" "member_of_parent" {
+		like_any_patterns = ["test_ds_roles_%"]
+		member_of         = ["test_ds_roles_parent"]
+	}
+
+	data "postgresql_roles" "createdb" {
+		like_any_patterns = ["test_ds_roles_%"]
+		options           = ["createdb"]
+	}
+
+	data "postgresql_roles" "system_false" {
+		like_any_patterns = ["root", "admin"]
+	}
+
+	data "postgresql_roles" "system_true" {
+		include_system_roles = true
+		like_any_patterns    = ["root", "admin"]
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_roles.like_ds_roles", "roles.#", "4"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.like_ds_roles", "roles.0.name", "test_ds_roles_app1"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.like_ds_roles", "roles.0.login", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.like_app_not_like_1", "roles.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.like_app_not_like_1", "roles.0.name", "test_ds_roles_app2"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.regex_app", "roles.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.member_of_parent", "roles.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.member_of_parent", "roles.0.name", "test_ds_roles_app1"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.member_of_parent", "roles.0.member_of.0", parentRole),
+					resource.TestCheckResourceAttr("data.postgresql_roles.member_of_parent", "roles.1.name", "test_ds_roles_other"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.createdb", "roles.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.createdb", "roles.0.name", "test_ds_roles_app2"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.createdb", "roles.0.create_database", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.system_false", "roles.#", "0"),
+					resource.TestCheckResourceAttr("data.postgresql_roles.system_true", "roles.#", "2"),
+				),
+			},
+		},
+	})
+}
@@ -19,7 +19,7 @@ const (
 
 func dataSourcePostgreSQLDatabaseTables() *schema.Resource {
 	return &schema.Resource{
-		Read: PGResourceFunc(dataSourcePostgreSQLTablesRead),
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLTablesRead),
 		Schema: map[string]*schema.Schema{
 			"database": {
 				Type:        schema.TypeString,
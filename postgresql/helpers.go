@@ -1,40 +1,87 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/blang/semver"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
-func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(*schema.ResourceData, interface{}) error {
-	return func(d *schema.ResourceData, meta interface{}) error {
+// PGResourceFunc wraps a CRUD function into a context-aware one: statements run through
+// the connection are cancelled with the context, and the warnings reported on the connection
+// are returned along with the error, if any.
+func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 		client := meta.(*Client)
 
-		db, err := client.Connect()
+		db, err := client.ConnectContext(ctx)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
-		return fn(db, d)
+		var diags diag.Diagnostics
+		if err := fn(db.withContext(ctx, &diags), d); err != nil {
+			return append(diags, errorDiagnostic(err))
+		}
+		return diags
 	}
 }
 
-func PGResourceExistsFunc(fn func(*DBConnection, *schema.ResourceData) (bool, error)) func(*schema.ResourceData, interface{}) (bool, error) {
-	return func(d *schema.ResourceData, meta interface{}) (bool, error) {
-		client := meta.(*Client)
+// attributeError is an error caused by the value of a resource attribute.
+type attributeError struct {
+	attribute string
+	err       error
+}
 
-		db, err := client.Connect()
-		if err != nil {
-			return false, err
-		}
+func newAttributeError(attribute string, err error) error {
+	return &attributeError{attribute: attribute, err: err}
+}
+
+func (e *attributeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *attributeError) Unwrap() error {
+	return e.err
+}
 
-		return fn(db, d)
+// errorDiagnostic converts err into a diagnostic, pointing at the attribute which caused it when known.
+func errorDiagnostic(err error) diag.Diagnostic {
+	diagnostic := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  err.Error(),
 	}
+
+	var attrErr *attributeError
+	if errors.As(err, &attrErr) {
+		diagnostic.AttributePath = cty.GetAttrPath(attrErr.attribute)
+	}
+	return diagnostic
+}
+
+const (
+	// SQLSTATE codes of errors caused by a missing privilege, and by statements which
+	// can't run inside a transaction or aren't supported in the current context.
+	pqErrorCodeInsufficientPrivilege = "42501"
+	pqErrorCodeActiveSQLTransaction  = "25001"
+	pqErrorCodeFeatureNotSupported   = "0A000"
+)
+
+// isPqErrorCode returns true if err is a CockroachDB error with the given SQLSTATE code.
+func isPqErrorCode(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
 }
 
 // QueryAble is a DB connection (sql.DB/Tx)
@@ -44,6 +91,78 @@ type QueryAble interface {
 	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
+// withTransaction runs fn inside a transaction which is committed if fn succeeds and
+// rolled back otherwise. The whole transaction is run again when it fails with a
+// retryable error, so fn must only depend on its arguments and the resource data.
+// The warnings fn reports on its connection are only kept for the attempt which commits.
+// If CockroachDB refuses one of the statements inside an explicit transaction, fn is run
+// again outside of a transaction, losing atomicity.
+func withTransaction(db *DBConnection, fn func(db *DBConnection, txn QueryAble) error) error {
+	var warnings diag.Diagnostics
+	err := db.withRetry("transaction", isRetryableError, func() error {
+		warnings = nil
+		attempt := db.withContext(db.context(), &warnings)
+
+		txn, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("could not start transaction: %w", err)
+		}
+		defer deferredRollback(txn)
+
+		if err := fn(attempt, txn); err != nil {
+			return err
+		}
+
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("could not commit transaction: %w", err)
+		}
+		return nil
+	})
+	if isNotSupportedInTransaction(err) {
+		log.Printf("[WARN] running statements outside of a transaction after error: %v", err)
+
+		refused := err
+		warnings = nil
+		attempt := db.withContext(db.context(), &warnings)
+		if err = fn(attempt, attempt); err == nil {
+			attempt.warn(
+				"",
+				"Changes were not applied atomically",
+				fmt.Sprintf("CockroachDB refused to run the statements in a transaction (%v), they were run one by one instead.", refused),
+			)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	db.addWarnings(warnings)
+	return nil
+}
+
+// isNotSupportedInTransaction returns true if err was returned for a statement which CockroachDB
+// doesn't run inside an explicit transaction, e.g. a schema change following a write.
+func isNotSupportedInTransaction(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case pqErrorCodeActiveSQLTransaction:
+		return true
+	case pqErrorCodeFeatureNotSupported:
+		return strings.Contains(pqErr.Message, "transaction")
+	}
+	return false
+}
+
+// deferredRollback rolls back the transaction unless it has already been committed.
+func deferredRollback(txn *sql.Tx) {
+	if err := txn.Rollback(); err != nil && err != sql.ErrTxDone {
+		log.Printf("[ERR] could not rollback transaction: %v", err)
+	}
+}
+
 // pqQuoteLiteral returns a string literal safe for inclusion in a PostgreSQL
 // query as a parameter.  The resulting string still needs to be wrapped in
 // single quotes in SQL (i.e. fmt.Sprintf(`'%s'`, pqQuoteLiteral("str"))).  See
@@ -56,10 +175,10 @@ func pqQuoteLiteral(in string) string {
 
 func isMemberOfRole(db QueryAble, role, member string) (bool, error) {
 	var _rez int
-	err := db.QueryRow(
+	err := queryRowScan(db,
 		"SELECT 1 FROM pg_auth_members WHERE pg_get_userbyid(roleid) = $1 AND pg_get_userbyid(member) = $2",
-		role, member,
-	).Scan(&_rez)
+		[]interface{}{role, member}, &_rez,
+	)
 
 	switch {
 	case err == sql.ErrNoRows:
@@ -71,6 +190,36 @@ func isMemberOfRole(db QueryAble, role, member string) (bool, error) {
 	return true, nil
 }
 
+// getInheritedRoles returns the roles *role* is a member of, directly or through
+// other roles, by walking pg_auth_members recursively.
+func getInheritedRoles(db QueryAble, role string) ([]string, error) {
+	rows, err := db.Query(
+		`WITH RECURSIVE memberships(role_name) AS (
+			SELECT $1::STRING
+			UNION
+			SELECT pg_get_userbyid(m.roleid)
+			FROM pg_auth_members m
+			JOIN memberships ON pg_get_userbyid(m.member) = memberships.role_name
+		)
+		SELECT role_name FROM memberships WHERE role_name != $1 ORDER BY role_name`,
+		role,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not read role memberships: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var roleName string
+		if err := rows.Scan(&roleName); err != nil {
+			return nil, fmt.Errorf("could not scan role membership: %w", err)
+		}
+		roles = append(roles, roleName)
+	}
+	return roles, rows.Err()
+}
+
 // grantRoleMembership grants the role *role* to the user *member*.
 // It returns false if the grant is not needed because the user is already
 // a member of this role.
@@ -144,11 +293,29 @@ var allowedPrivileges = map[string][]string{
 	"type":      {"ALL", "USAGE"},
 }
 
+// privilegesSupportedCockroachdb maps privileges to the CockroachDB versions supporting them.
+// Privileges not listed here are supported by every version handled by the provider.
+var privilegesSupportedCockroachdb = map[string]semver.Range{
+	"CHANGEFEED":               semver.MustParseRange(">=22.1.0"),
+	"BACKUP":                   semver.MustParseRange(">=22.2.0"),
+	"RESTORE":                  semver.MustParseRange(">=22.2.0"),
+	"EXTERNALCONNECTION":       semver.MustParseRange(">=22.2.0"),
+	"EXTERNALIOIMPLICITACCESS": semver.MustParseRange(">=22.2.0"),
+	"VIEWJOB":                  semver.MustParseRange(">=23.1.0"),
+	"VIEWSYSTEMTABLE":          semver.MustParseRange(">=23.1.0"),
+	"REPLICATION":              semver.MustParseRange(">=23.2.0"),
+}
+
 // validatePrivileges checks that privileges to apply are allowed for this object type.
 func validatePrivileges(d *schema.ResourceData) error {
-	objectType := d.Get("object_type").(string)
-	privileges := d.Get("privileges").(*schema.Set).List()
+	if err := validateObjectTypePrivileges(d.Get("object_type").(string), d.Get("privileges").(*schema.Set).List()); err != nil {
+		return newAttributeError("privileges", err)
+	}
+	return nil
+}
 
+// validateObjectTypePrivileges checks that privileges are allowed for the object type.
+func validateObjectTypePrivileges(objectType string, privileges []interface{}) error {
 	allowed, ok := allowedPrivileges[objectType]
 	if !ok {
 		return fmt.Errorf("unknown object type %s", objectType)
@@ -162,6 +329,17 @@ func validatePrivileges(d *schema.ResourceData) error {
 	return nil
 }
 
+// validatePrivilegesSupported checks that privileges are supported by the connected CockroachDB version.
+func validatePrivilegesSupported(db *DBConnection, privileges []interface{}) error {
+	for _, priv := range privileges {
+		supported, found := privilegesSupportedCockroachdb[priv.(string)]
+		if found && !supported(db.version) {
+			return fmt.Errorf("privilege %s is not supported for this version (%s)", priv, db.version)
+		}
+	}
+	return nil
+}
+
 func pgArrayToSet(arr pq.ByteaArray) *schema.Set {
 	s := make([]interface{}, len(arr))
 	for i, v := range arr {
@@ -178,6 +356,16 @@ func stringSliceToSet(slice []string) *schema.Set {
 	return schema.NewSet(schema.HashString, s)
 }
 
+// setToSortedList returns the elements of a string set in sorted order.
+func setToSortedList(set *schema.Set) []string {
+	values := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		values = append(values, v.(string))
+	}
+	sort.Strings(values)
+	return values
+}
+
 func quoteIdentifyIdent(ident string) string {
 	// When passing a function with arguments like "test(text, char)" this will correctly parse it to "test"(text, char).
 	// If we were to add quotes around the whole ident postgres would not be able to find the function.
@@ -224,7 +412,11 @@ func connectToDatabase(db *DBConnection, database string) (*DBConnection, error)
 	if database == "" || database == db.client.databaseName {
 		return db, nil
 	}
-	return db.client.config.NewClient(database).Connect()
+	conn, err := db.client.config.NewClient(database).ConnectContext(db.context())
+	if err != nil {
+		return nil, err
+	}
+	return conn.withContext(db.ctx, db.warnings), nil
 }
 
 // getDatabases returns the names of all non-template, non-system databases.
@@ -248,8 +440,55 @@ func getDatabases(db QueryAble) ([]string, error) {
 	return databases, rows.Err()
 }
 
+// filterDatabases returns the databases which are in include (when not empty) and not in exclude.
+func filterDatabases(databases, include, exclude []string) []string {
+	filtered := make([]string, 0, len(databases))
+	for _, database := range databases {
+		if len(include) > 0 && !sliceContainsStr(include, database) {
+			continue
+		}
+		if sliceContainsStr(exclude, database) {
+			continue
+		}
+		filtered = append(filtered, database)
+	}
+	return filtered
+}
+
+// forEachDatabase runs fn with a connection to each database, running at most parallelism
+// calls concurrently. The errors of all databases are returned together.
+func forEachDatabase(db *DBConnection, databases []string, parallelism int, fn func(dbConn *DBConnection, database string) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	errs := make([]error, len(databases))
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, database := range databases {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, database string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			dbConn, err := connectToDatabase(db, database)
+			if err != nil {
+				errs[i] = fmt.Errorf("could not connect to database %s: %w", database, err)
+				return
+			}
+			errs[i] = fn(dbConn, database)
+		}(i, database)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
 func dbExists(db QueryAble, dbname string) (bool, error) {
-	err := db.QueryRow("SELECT datname FROM pg_database WHERE datname=$1", dbname).Scan(&dbname)
+	err := queryRowScan(db, "SELECT datname FROM pg_database WHERE datname=$1", []interface{}{dbname}, &dbname)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -261,7 +500,7 @@ func dbExists(db QueryAble, dbname string) (bool, error) {
 }
 
 func roleExists(db QueryAble, rolname string) (bool, error) {
-	err := db.QueryRow("SELECT 1 FROM pg_roles WHERE rolname=$1", rolname).Scan(&rolname)
+	err := queryRowScan(db, "SELECT 1 FROM pg_roles WHERE rolname=$1", []interface{}{rolname}, &rolname)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -273,7 +512,7 @@ func roleExists(db QueryAble, rolname string) (bool, error) {
 }
 
 func schemaExists(db QueryAble, schemaname string) (bool, error) {
-	err := db.QueryRow("SELECT 1 FROM pg_namespace WHERE nspname=$1", schemaname).Scan(&schemaname)
+	err := queryRowScan(db, "SELECT 1 FROM pg_namespace WHERE nspname=$1", []interface{}{schemaname}, &schemaname)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -284,8 +523,42 @@ func schemaExists(db QueryAble, schemaname string) (bool, error) {
 	return true, nil
 }
 
+// objectExists checks that an object of the given grant object type exists in the schema.
+// Function arguments (e.g. "fn(int)") are ignored.
+func objectExists(db QueryAble, objectType, schemaName, objectName string) (bool, error) {
+	var query string
+	switch objectType {
+	case "table":
+		query = `SELECT 1 FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'v', 'm')`
+	case "sequence":
+		query = `SELECT 1 FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'S'`
+	case "function", "procedure", "routine":
+		query = `SELECT 1 FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1 AND p.proname = $2 LIMIT 1`
+		objectName = strings.Split(objectName, "(")[0]
+	case "type":
+		query = `SELECT 1 FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+			WHERE n.nspname = $1 AND t.typname = $2`
+	default:
+		return false, fmt.Errorf("cannot check existence of object type %s", objectType)
+	}
+
+	var exists int
+	err := queryRowScan(db, query, []interface{}{schemaName, objectName}, &exists)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("could not check if %s %s.%s exists: %w", objectType, schemaName, objectName, err)
+	}
+
+	return true, nil
+}
+
 func schemaExistsWithDB(db *DBConnection, schemaname string) (bool, error) {
-	err := db.QueryRow("SELECT 1 FROM pg_namespace WHERE nspname=$1", schemaname).Scan(&schemaname)
+	err := queryRowScan(db, "SELECT 1 FROM pg_namespace WHERE nspname=$1", []interface{}{schemaname}, &schemaname)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -1,8 +1,15 @@
 package postgresql
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/blang/semver"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,3 +24,79 @@ func TestFindStringSubmatchMap(t *testing.T) {
 		},
 	)
 }
+
+func TestValidatePrivilegesSupported(t *testing.T) {
+	db := &DBConnection{version: semver.MustParse("22.1.0")}
+
+	assert.NoError(t, validatePrivilegesSupported(db, []interface{}{"SELECT", "CHANGEFEED"}))
+	assert.Error(t, validatePrivilegesSupported(db, []interface{}{"BACKUP"}))
+	assert.Error(t, validatePrivilegesSupported(db, []interface{}{"VIEWJOB"}))
+}
+
+func TestFilterDatabases(t *testing.T) {
+	databases := []string{"db1", "db2", "db3"}
+
+	assert.Equal(t, databases, filterDatabases(databases, nil, nil))
+	assert.Equal(t, []string{"db1", "db3"}, filterDatabases(databases, []string{"db1", "db3", "missing"}, nil))
+	assert.Equal(t, []string{"db2", "db3"}, filterDatabases(databases, nil, []string{"db1"}))
+	assert.Equal(t, []string{"db3"}, filterDatabases(databases, []string{"db1", "db3"}, []string{"db1"}))
+}
+
+func TestErrorDiagnostic(t *testing.T) {
+	diagnostic := errorDiagnostic(errors.New("could not connect"))
+	assert.Equal(t, diag.Error, diagnostic.Severity)
+	assert.Equal(t, "could not connect", diagnostic.Summary)
+	assert.Nil(t, diagnostic.AttributePath)
+
+	err := fmt.Errorf("feature is not supported: %w", newAttributeError("object_type", errors.New("object type ROUTINE is not supported")))
+	diagnostic = errorDiagnostic(err)
+	assert.Equal(t, diag.Error, diagnostic.Severity)
+	assert.Equal(t, "feature is not supported: object type ROUTINE is not supported", diagnostic.Summary)
+	assert.Equal(t, cty.GetAttrPath("object_type"), diagnostic.AttributePath)
+}
+
+func TestDBConnectionWarn(t *testing.T) {
+	var diags diag.Diagnostics
+	db := (&DBConnection{version: semver.MustParse("22.1.0")}).withContext(context.Background(), &diags)
+
+	db.warn(roleBypassRLSAttr, "bypass_row_level_security is not supported", "BYPASSRLS was not granted")
+
+	assert.Equal(t, diag.Diagnostics{{
+		Severity:      diag.Warning,
+		Summary:       "bypass_row_level_security is not supported",
+		Detail:        "BYPASSRLS was not granted",
+		AttributePath: cty.GetAttrPath(roleBypassRLSAttr),
+	}}, diags)
+	assert.False(t, diags.HasError())
+
+	// Warnings about the whole resource have no attribute path, and warnings collected
+	// on another connection are appended.
+	var attempt diag.Diagnostics
+	db.withContext(context.Background(), &attempt).warn("", "not applied atomically", "")
+	db.addWarnings(attempt)
+	assert.Len(t, diags, 2)
+	assert.Nil(t, diags[1].AttributePath)
+
+	// Warnings reported without an operation are only logged.
+	(&DBConnection{}).warn(roleBypassRLSAttr, "ignored", "")
+}
+
+func TestIsNotSupportedInTransaction(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{err: nil, expected: false},
+		{err: errors.New("not supported in a transaction"), expected: false},
+		{err: &pq.Error{Code: "25001", Message: "CREATE DATABASE cannot run inside a transaction block"}, expected: true},
+		{err: fmt.Errorf("error creating role: %w", &pq.Error{Code: "0A000", Message: "unimplemented: schema change statement cannot follow a statement that has written in the same transaction"}), expected: true},
+		{err: &pq.Error{Code: "0A000", Message: "unimplemented: column families"}, expected: false},
+		{err: &pq.Error{Code: "40001"}, expected: false},
+	}
+
+	for _, c := range cases {
+		if out := isNotSupportedInTransaction(c.err); out != c.expected {
+			t.Fatalf("Error matching output and expected for %v: %#v vs %#v", c.err, out, c.expected)
+		}
+	}
+}
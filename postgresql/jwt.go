@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jwtAuthOption = "--crdb:jwt_auth_enabled=true"
+
+	// jwtRefreshMargin is how long before its expiry a cached token is refreshed.
+	jwtRefreshMargin = 30 * time.Second
+
+	// jwtDefaultTTL is how long a token without an exp claim is cached, as the output of password_command.
+	jwtDefaultTTL = defaultPasswordCommandTTL
+)
+
+// JWTConfig - JWT token authentication, the token being sent as the password.
+type JWTConfig struct {
+	Token        string
+	TokenFile    string
+	TokenCommand []string
+
+	lock      sync.Mutex
+	cached    string
+	expiresAt time.Time
+}
+
+// token returns the token to authenticate with. Tokens read from a file or returned by
+// a command are cached until shortly before the expiry of their exp claim, or for
+// jwtDefaultTTL when they have none.
+func (j *JWTConfig) token(ctx context.Context) (string, error) {
+	if j.Token != "" {
+		return j.Token, nil
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.cached != "" && time.Now().Before(j.expiresAt) {
+		return j.cached, nil
+	}
+
+	var token string
+	var err error
+	if j.TokenFile != "" {
+		token, err = readSecretFile("JWT token", j.TokenFile)
+	} else {
+		token, err = runSecretCommand(ctx, "JWT token", j.TokenCommand)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	j.cached = token
+	if expiry, ok := jwtExpiry(token); ok {
+		j.expiresAt = expiry.Add(-jwtRefreshMargin)
+	} else {
+		j.expiresAt = time.Now().Add(jwtDefaultTTL)
+	}
+	return token, nil
+}
+
+// invalidate drops the cached token, so that it is read again for the next connection.
+func (j *JWTConfig) invalidate() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.cached = ""
+}
+
+// jwtExpiry returns the time of the exp claim of a JWT token, without verifying it.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(*claims.Exp), 0), true
+}
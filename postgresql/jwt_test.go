@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return fmt.Sprintf("%s.%s.%s", encode([]byte(`{"alg":"RS256"}`)), encode([]byte(claims)), encode([]byte("signature")))
+}
+
+func TestJWTExpiry(t *testing.T) {
+	if expiry, ok := jwtExpiry(testJWT(`{"sub":"ci","exp":1893456000}`)); !ok || !expiry.Equal(time.Unix(1893456000, 0)) {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", expiry, time.Unix(1893456000, 0))
+	}
+	for _, token := range []string{"not-a-jwt", testJWT(`{"sub":"ci"}`), testJWT(`not json`)} {
+		if _, ok := jwtExpiry(token); ok {
+			t.Fatalf("expected no expiry for token %q", token)
+		}
+	}
+}
+
+func TestJWTConfigToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeToken := func(token string) {
+		if err := os.WriteFile(tokenFile, []byte(token+"\n"), 0600); err != nil {
+			t.Fatalf("could not write token file: %v", err)
+		}
+	}
+
+	// Tokens without an exp claim are cached for jwtDefaultTTL, or until they are refused.
+	config := &JWTConfig{TokenFile: tokenFile}
+	writeToken("first")
+	if token, err := config.token(context.Background()); err != nil || token != "first" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, "first", err)
+	}
+	writeToken("second")
+	if token, err := config.token(context.Background()); err != nil || token != "first" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, "first", err)
+	}
+	(&Config{JWT: config}).invalidatePassword()
+	if token, err := config.token(context.Background()); err != nil || token != "second" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, "second", err)
+	}
+
+	// Tokens are cached until they are about to expire.
+	config = &JWTConfig{TokenFile: tokenFile}
+	valid := testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix()))
+	writeToken(valid)
+	if token, err := config.token(context.Background()); err != nil || token != valid {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, valid, err)
+	}
+	writeToken("refreshed")
+	if token, err := config.token(context.Background()); err != nil || token != valid {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, valid, err)
+	}
+
+	expiring := testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(jwtRefreshMargin/2).Unix()))
+	config = &JWTConfig{TokenFile: tokenFile}
+	writeToken(expiring)
+	if _, err := config.token(context.Background()); err != nil {
+		t.Fatalf("could not read token: %v", err)
+	}
+	writeToken("refreshed")
+	if token, err := config.token(context.Background()); err != nil || token != "refreshed" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, "refreshed", err)
+	}
+
+	config = &JWTConfig{TokenCommand: []string{"echo", "from-command"}}
+	if token, err := config.token(context.Background()); err != nil || token != "from-command" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", token, "from-command", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (&JWTConfig{TokenCommand: []string{"sleep", "10"}}).token(ctx); err == nil {
+		t.Fatal("expected an error for a cancelled token command")
+	}
+
+	writeToken("")
+	if _, err := (&JWTConfig{TokenFile: tokenFile}).token(context.Background()); err == nil {
+		t.Fatal("expected an error for an empty token")
+	}
+}
@@ -0,0 +1,153 @@
+package postgresql
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	scramSHA256Prefix = "SCRAM-SHA-256$"
+
+	// scramMatchesMaxSize bounds the number of roles whose last password check is cached.
+	scramMatchesMaxSize = 1024
+)
+
+var (
+	// scramMatches caches the last result of ScramVerifier.Matches of each role, as every
+	// refresh compares the same password again. The verifier and the password are only kept
+	// as a keyed digest, whose key is random for each run of the provider.
+	scramMatchesLock sync.Mutex
+	scramMatches     = make(map[string]scramMatch)
+	scramMatchesKey  = rand.Text()
+)
+
+type scramMatch struct {
+	digest  []byte
+	matches bool
+}
+
+// ScramVerifier is the model for a SCRAM-SHA-256 password verifier, stored as
+// SCRAM-SHA-256$<iterations>:<salt>$<stored key>:<server key>
+type ScramVerifier struct {
+	Iterations int
+	Salt       []byte
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// ParseScramVerifier parses a SCRAM-SHA-256 verifier with base64 encoded salt and keys.
+func ParseScramVerifier(verifier string) (*ScramVerifier, error) {
+	if !strings.HasPrefix(verifier, scramSHA256Prefix) {
+		return nil, fmt.Errorf("password hash must start with %q", scramSHA256Prefix)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(verifier, scramSHA256Prefix), "$")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("password hash must have the form SCRAM-SHA-256$<iterations>:<salt>$<stored key>:<server key>")
+	}
+
+	iterationsAndSalt := strings.Split(parts[0], ":")
+	keys := strings.Split(parts[1], ":")
+	if len(iterationsAndSalt) != 2 || len(keys) != 2 {
+		return nil, fmt.Errorf("password hash must have the form SCRAM-SHA-256$<iterations>:<salt>$<stored key>:<server key>")
+	}
+
+	iterations, err := strconv.Atoi(iterationsAndSalt[0])
+	if err != nil || iterations <= 0 {
+		return nil, fmt.Errorf("invalid SCRAM iteration count %q", iterationsAndSalt[0])
+	}
+
+	verifierModel := &ScramVerifier{Iterations: iterations}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *[]byte
+	}{
+		{"salt", iterationsAndSalt[1], &verifierModel.Salt},
+		{"stored key", keys[0], &verifierModel.StoredKey},
+		{"server key", keys[1], &verifierModel.ServerKey},
+	} {
+		decoded, err := base64.StdEncoding.DecodeString(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCRAM %s: %w", field.name, err)
+		}
+		*field.dest = decoded
+	}
+
+	if len(verifierModel.StoredKey) != sha256.Size || len(verifierModel.ServerKey) != sha256.Size {
+		return nil, fmt.Errorf("SCRAM stored and server keys must be %d bytes long", sha256.Size)
+	}
+
+	return verifierModel, nil
+}
+
+// Matches returns true if the verifier was computed from the given password.
+func (v *ScramVerifier) Matches(password string) (bool, error) {
+	saltedPassword, err := pbkdf2.Key(sha256.New, password, v.Salt, v.Iterations, sha256.Size)
+	if err != nil {
+		return false, err
+	}
+
+	mac := hmac.New(sha256.New, saltedPassword)
+	mac.Write([]byte("Client Key"))
+	storedKey := sha256.Sum256(mac.Sum(nil))
+
+	return hmac.Equal(storedKey[:], v.StoredKey), nil
+}
+
+// matchesCached is Matches, only deriving the keys again when the verifier or the password
+// of the role changed since its last check, as the derivation is deliberately slow.
+func (v *ScramVerifier) matchesCached(role, password string) (bool, error) {
+	mac := hmac.New(sha256.New, []byte(scramMatchesKey))
+	fmt.Fprintf(mac, "%d$%x$%x$%s", v.Iterations, v.Salt, v.StoredKey, password)
+	digest := mac.Sum(nil)
+
+	scramMatchesLock.Lock()
+	cached, ok := scramMatches[role]
+	scramMatchesLock.Unlock()
+	if ok && hmac.Equal(cached.digest, digest) {
+		return cached.matches, nil
+	}
+
+	matches, err := v.Matches(password)
+	if err != nil {
+		return false, err
+	}
+
+	scramMatchesLock.Lock()
+	defer scramMatchesLock.Unlock()
+	if _, ok := scramMatches[role]; !ok && len(scramMatches) >= scramMatchesMaxSize {
+		// Evict any role, the cache only saves the derivation of the next refresh.
+		for evicted := range scramMatches {
+			delete(scramMatches, evicted)
+			break
+		}
+	}
+	scramMatches[role] = scramMatch{digest: digest, matches: matches}
+	return matches, nil
+}
+
+// Equal returns true if both verifiers have the same salt, iteration count and keys,
+// whatever their encoding.
+func (v *ScramVerifier) Equal(other *ScramVerifier) bool {
+	return v.Iterations == other.Iterations &&
+		bytes.Equal(v.Salt, other.Salt) &&
+		bytes.Equal(v.StoredKey, other.StoredKey) &&
+		bytes.Equal(v.ServerKey, other.ServerKey)
+}
+
+// validateScramVerifier is a schema.SchemaValidateFunc for SCRAM-SHA-256 verifiers.
+func validateScramVerifier(v interface{}, key string) (warnings []string, errors []error) {
+	if _, err := ParseScramVerifier(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("invalid %s: %w", key, err))
+	}
+	return
+}
@@ -0,0 +1,115 @@
+package postgresql
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestScramVerifier computes the SCRAM-SHA-256 verifier of password as CockroachDB stores it.
+func newTestScramVerifier(t *testing.T, password string) string {
+	return newTestScramVerifierWith(t, password, []byte("0123456789abcdef"), 4096)
+}
+
+// newTestScramVerifierWith computes the SCRAM-SHA-256 verifier of password with the given salt and iteration count.
+func newTestScramVerifierWith(t *testing.T, password string, salt []byte, iterations int) string {
+	saltedPassword, err := pbkdf2.Key(sha256.New, password, salt, iterations, sha256.Size)
+	if err != nil {
+		t.Fatalf("could not compute salted password: %v", err)
+	}
+
+	clientKey := hmac.New(sha256.New, saltedPassword)
+	clientKey.Write([]byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey.Sum(nil))
+
+	serverKey := hmac.New(sha256.New, saltedPassword)
+	serverKey.Write([]byte("Server Key"))
+
+	return fmt.Sprintf(
+		"SCRAM-SHA-256$%d:%s$%s:%s",
+		iterations,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(storedKey[:]),
+		base64.StdEncoding.EncodeToString(serverKey.Sum(nil)),
+	)
+}
+
+func TestParseScramVerifier(t *testing.T) {
+	verifier, err := ParseScramVerifier(newTestScramVerifier(t, "secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4096, verifier.Iterations)
+	assert.Equal(t, []byte("0123456789abcdef"), verifier.Salt)
+
+	matches, err := verifier.Matches("secret")
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = verifier.Matches("other")
+	assert.NoError(t, err)
+	assert.False(t, matches)
+
+	for _, invalid := range []string{
+		"",
+		"$2a$10$abcdefghijklmnopqrstuu",
+		"SCRAM-SHA-256$4096:c2FsdA==",
+		"SCRAM-SHA-256$abc:c2FsdA==$a2V5:a2V5",
+		"SCRAM-SHA-256$4096:c2FsdA==$a2V5:a2V5",
+	} {
+		_, err := ParseScramVerifier(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestScramVerifierMatchesCached(t *testing.T) {
+	verifier, err := ParseScramVerifier(newTestScramVerifier(t, "cached"))
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		matches, err := verifier.matchesCached("role", "cached")
+		assert.NoError(t, err)
+		assert.True(t, matches)
+
+		matches, err = verifier.matchesCached("role", "other")
+		assert.NoError(t, err)
+		assert.False(t, matches)
+	}
+
+	// A different verifier of the same password isn't answered from the cache, and
+	// replaces the entry of the role.
+	verifier.StoredKey = make([]byte, len(verifier.StoredKey))
+	matches, err := verifier.matchesCached("role", "cached")
+	assert.NoError(t, err)
+	assert.False(t, matches)
+	assert.Len(t, scramMatches, 1)
+
+	// The cache doesn't grow past its maximum size.
+	for i := 0; len(scramMatches) < scramMatchesMaxSize; i++ {
+		scramMatches[fmt.Sprintf("role_%d", i)] = scramMatch{}
+	}
+	_, err = verifier.matchesCached("new_role", "cached")
+	assert.NoError(t, err)
+	assert.Len(t, scramMatches, scramMatchesMaxSize)
+	assert.Contains(t, scramMatches, "new_role")
+}
+
+func TestScramVerifierEqual(t *testing.T) {
+	verifier, err := ParseScramVerifier(newTestScramVerifier(t, "secret"))
+	assert.NoError(t, err)
+
+	// Verifiers are compared by value.
+	same := *verifier
+	assert.True(t, verifier.Equal(&same))
+
+	rehashed, err := ParseScramVerifier(newTestScramVerifierWith(t, "secret", []byte("fedcba9876543210"), 10610))
+	assert.NoError(t, err)
+	assert.False(t, verifier.Equal(rehashed))
+
+	other, err := ParseScramVerifier(newTestScramVerifier(t, "other"))
+	assert.NoError(t, err)
+	assert.False(t, verifier.Equal(other))
+}
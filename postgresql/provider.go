@@ -2,12 +2,16 @@ package postgresql
 
 import (
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+var virtualClusterNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*$`)
+
 const (
 	defaultProviderMaxOpenConnections = 20
 	defaultExpectedCockroachDBVersion = "22.2.0"
@@ -23,12 +27,31 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("PGHOST", nil),
 				Description: "Name of CockroachDB server address to connect to",
 			},
+			"hosts": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Addresses of the CockroachDB nodes to connect to, as `host` or `host:port`, tried in turn until one accepts the connection. Takes precedence over `host`",
+			},
+			"load_balance_hosts": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      loadBalanceHostsDisable,
+				Description:  "Order in which `hosts` are tried for each new connection: `disable` tries them in the listed order, `random` in a random order",
+				ValidateFunc: validation.StringInSlice([]string{loadBalanceHostsDisable, loadBalanceHostsRandom}, false),
+			},
 			"port": {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PGPORT", 26257),
 				Description: "The CockroachDB port number to connect to at the server host",
 			},
+			"virtual_cluster": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Name of the CockroachDB virtual cluster (tenant) to connect to, selected with the `-ccluster` connection option",
+				ValidateFunc: validation.StringMatch(virtualClusterNameRegexp, "must contain only letters, digits and hyphens, and start with a letter or a digit"),
+			},
 			"database": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -48,6 +71,27 @@ func Provider() *schema.Provider {
 				Description: "Password for authentication",
 				Sensitive:   true,
 			},
+			"password_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path of a file containing the password, read for every new connection",
+				ConflictsWith: []string{"password", "password_command"},
+			},
+			"password_command": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				MinItems:      1,
+				Description:   "Command (program followed by its arguments) printing the password on its standard output",
+				ConflictsWith: []string{"password", "password_file"},
+			},
+			"password_command_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(defaultPasswordCommandTTL / time.Second),
+				Description:  "Number of seconds the output of `password_command` is cached for. Zero runs the command for every new connection.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 
 			"database_username": {
 				Type:        schema.TypeString,
@@ -91,6 +135,119 @@ func Provider() *schema.Provider {
 				},
 				MaxItems: 1,
 			},
+			"jwt": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Authenticate with a JWT token, sent as the password with JWT authentication enabled in the connection options. The `password` attribute is ignored.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"token": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							Description:  "The JWT token.",
+							ExactlyOneOf: []string{"jwt.0.token", "jwt.0.token_file", "jwt.0.token_command"},
+						},
+						"token_file": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Path of a file containing the JWT token, read again for new connections once the token has expired.",
+							ExactlyOneOf: []string{"jwt.0.token", "jwt.0.token_file", "jwt.0.token_command"},
+						},
+						"token_command": {
+							Type:         schema.TypeList,
+							Optional:     true,
+							Elem:         &schema.Schema{Type: schema.TypeString},
+							MinItems:     1,
+							Description:  "Command (program followed by its arguments) printing the JWT token, run again for new connections once the token has expired, or after 5 minutes when it has no `exp` claim.",
+							ExactlyOneOf: []string{"jwt.0.token", "jwt.0.token_file", "jwt.0.token_command"},
+						},
+					},
+				},
+				MaxItems: 1,
+			},
+			"proxy": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Proxy to connect through, instead of the one set in the `ALL_PROXY` and `NO_PROXY` environment variables.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "URL of the proxy: `socks5://` or `socks5h://` for a SOCKS5 proxy, `http://` or `https://` for an HTTP CONNECT proxy.",
+							ValidateFunc: validation.IsURLWithScheme([]string{"socks5", "socks5h", "http", "https"}),
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "User name to authenticate to the proxy with, instead of the one of the URL.",
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Password to authenticate to the proxy with, instead of the one of the URL.",
+						},
+						"no_proxy": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Comma-separated hosts, domains, IP addresses and CIDR ranges connected to directly, in the `NO_PROXY` format.",
+						},
+					},
+				},
+				MaxItems: 1,
+			},
+			"ssh_tunnel": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "SSH bastion to tunnel the connections through, over a single SSH connection per provider configuration.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Address of the bastion, as `host` or `host:port` (port 22 by default).",
+						},
+						"user": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "User name to log in to the bastion as.",
+						},
+						"private_key": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Sensitive:     true,
+							Description:   "PEM encoded private key to authenticate with.",
+							ConflictsWith: []string{"ssh_tunnel.0.private_key_file"},
+						},
+						"private_key_file": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Description:   "Path of the private key to authenticate with.",
+							ConflictsWith: []string{"ssh_tunnel.0.private_key"},
+						},
+						"private_key_passphrase": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Passphrase of the private key, if it is encrypted.",
+						},
+						"use_agent": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Authenticate with the keys of the SSH agent listening on `SSH_AUTH_SOCK`.",
+						},
+						"known_hosts_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Path of the known_hosts file the host key of the bastion is verified against (defaults to `~/.ssh/known_hosts`).",
+						},
+					},
+				},
+				MaxItems: 1,
+			},
 			"sslrootcert": {
 				Type:        schema.TypeString,
 				Description: "The SSL server root certificate file path. The file must contain PEM encoded data.",
@@ -111,6 +268,34 @@ func Provider() *schema.Provider {
 				Description:  "Maximum number of connections to establish to the database. Zero means unlimited.",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			"max_idle_connections": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of idle connections kept open per database, to reuse them instead of connecting again for every statement. Zero keeps none.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"conn_max_lifetime": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of seconds a connection is reused for. Zero means unlimited.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"conn_max_idle_time": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of seconds a connection is kept idle before being closed. Zero means unlimited.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderMaxRetries,
+				Description:  "Maximum number of times a statement failing with a CockroachDB serialization (40001) or ambiguous commit (40003) error is retried. Zero disables retries.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 			"expected_version": {
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -127,21 +312,34 @@ func Provider() *schema.Provider {
 			"postgresql_grant_role":               resourcePostgreSQLGrantRole(),
 			"postgresql_schema":                   resourcePostgreSQLSchema(),
 			"postgresql_role":                     resourcePostgreSQLRole(),
+			"postgresql_role_session_defaults":    resourcePostgreSQLRoleSessionDefaults(),
+			"postgresql_role_members":             resourcePostgreSQLRoleMembers(),
 			"postgresql_function":                 resourcePostgreSQLFunction(),
 			"postgresql_crdb_changefeed":          resourceCockroachDBChangefeed(),
 			"postgresql_crdb_external_connection": resourceCockroachDBExternalConnection(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"postgresql_schemas":   dataSourcePostgreSQLDatabaseSchemas(),
-			"postgresql_tables":    dataSourcePostgreSQLDatabaseTables(),
-			"postgresql_sequences": dataSourcePostgreSQLDatabaseSequences(),
+			"postgresql_schemas":               dataSourcePostgreSQLDatabaseSchemas(),
+			"postgresql_tables":                dataSourcePostgreSQLDatabaseTables(),
+			"postgresql_sequences":             dataSourcePostgreSQLDatabaseSequences(),
+			"postgresql_effective_privileges":  dataSourcePostgreSQLEffectivePrivileges(),
+			"postgresql_role":                  dataSourcePostgreSQLRole(),
+			"postgresql_roles":                 dataSourcePostgreSQLRoles(),
+			"postgresql_role_membership_graph": dataSourcePostgreSQLRoleMembershipGraph(),
 		},
 
 		ConfigureFunc: providerConfigure,
 	}
 }
 
+// Shutdown releases the resources held by the provider configurations, the connection
+// pools and SSH tunnels, once the provider stops serving.
+func Shutdown() {
+	closeDBRegistry()
+	closeSSHTunnels()
+}
+
 func validateExpectedVersion(v interface{}, key string) (warnings []string, errors []error) {
 	if _, err := semver.ParseTolerant(v.(string)); err != nil {
 		errors = append(errors, fmt.Errorf("invalid version (%q): %w", v.(string), err))
@@ -165,18 +363,38 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	config := Config{
 		Scheme:            "postgres",
 		Host:              d.Get("host").(string),
+		LoadBalanceHosts:  d.Get("load_balance_hosts").(string),
 		Port:              d.Get("port").(int),
 		Username:          d.Get("username").(string),
 		Password:          d.Get("password").(string),
+		PasswordFile:      d.Get("password_file").(string),
 		DatabaseUsername:  d.Get("database_username").(string),
 		SSLMode:           sslMode,
 		ApplicationName:   "Terraform provider",
+		VirtualCluster:    d.Get("virtual_cluster").(string),
 		ConnectTimeoutSec: d.Get("connect_timeout").(int),
 		MaxConns:          d.Get("max_connections").(int),
+		MaxIdleConns:      d.Get("max_idle_connections").(int),
+		ConnMaxLifetime:   time.Duration(d.Get("conn_max_lifetime").(int)) * time.Second,
+		ConnMaxIdleTime:   time.Duration(d.Get("conn_max_idle_time").(int)) * time.Second,
+		MaxRetries:        d.Get("max_retries").(int),
 		ExpectedVersion:   version,
 		SSLRootCertPath:   d.Get("sslrootcert").(string),
 	}
 
+	if value, ok := d.GetOk("password_command"); ok {
+		config.PasswordCommand = &PasswordCommandConfig{
+			TTL: time.Duration(d.Get("password_command_ttl").(int)) * time.Second,
+		}
+		for _, arg := range value.([]interface{}) {
+			config.PasswordCommand.Command = append(config.PasswordCommand.Command, arg.(string))
+		}
+	}
+
+	for _, host := range d.Get("hosts").([]interface{}) {
+		config.Hosts = append(config.Hosts, host.(string))
+	}
+
 	if value, ok := d.GetOk("clientcert"); ok {
 		if spec, ok := value.([]interface{})[0].(map[string]interface{}); ok {
 			config.SSLClientCert = &ClientCertificateConfig{
@@ -187,6 +405,43 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		}
 	}
 
+	if value, ok := d.GetOk("jwt"); ok {
+		if spec, ok := value.([]interface{})[0].(map[string]interface{}); ok {
+			config.JWT = &JWTConfig{
+				Token:     spec["token"].(string),
+				TokenFile: spec["token_file"].(string),
+			}
+			for _, arg := range spec["token_command"].([]interface{}) {
+				config.JWT.TokenCommand = append(config.JWT.TokenCommand, arg.(string))
+			}
+		}
+	}
+
+	if value, ok := d.GetOk("proxy"); ok {
+		if spec, ok := value.([]interface{})[0].(map[string]interface{}); ok {
+			config.Proxy = &ProxyConfig{
+				URL:      spec["url"].(string),
+				Username: spec["username"].(string),
+				Password: spec["password"].(string),
+				NoProxy:  spec["no_proxy"].(string),
+			}
+		}
+	}
+
+	if value, ok := d.GetOk("ssh_tunnel"); ok {
+		if spec, ok := value.([]interface{})[0].(map[string]interface{}); ok {
+			config.SSHTunnel = &SSHTunnelConfig{
+				Host:                 spec["host"].(string),
+				User:                 spec["user"].(string),
+				PrivateKey:           spec["private_key"].(string),
+				PrivateKeyFile:       spec["private_key_file"].(string),
+				PrivateKeyPassphrase: spec["private_key_passphrase"].(string),
+				UseAgent:             spec["use_agent"].(bool),
+				KnownHostsFile:       spec["known_hosts_file"].(string),
+			}
+		}
+	}
+
 	client := config.NewClient(d.Get("database").(string))
 	return client, nil
 }
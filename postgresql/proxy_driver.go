@@ -1,10 +1,16 @@
 package postgresql
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
 	"net"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/lib/pq"
@@ -13,21 +19,153 @@ import (
 
 const proxyDriverName = "postgresql-proxy"
 
-type proxyDriver struct{}
+// ProxyConfig - proxy the connections are opened through, instead of the one set in the environment.
+type ProxyConfig struct {
+	URL      string
+	Username string
+	Password string
+	NoProxy  string
+}
+
+// proxyDriver dials through the proxy of the provider configuration or, when there is
+// none, the proxy set in the ALL_PROXY and NO_PROXY environment variables. With an SSH
+// tunnel, the proxy is used to reach the bastion, which opens the connections.
+type proxyDriver struct {
+	proxy     *ProxyConfig
+	sshTunnel *SSHTunnelConfig
+}
 
 func (d proxyDriver) Open(name string) (driver.Conn, error) {
 	return pq.DialOpen(d, name)
 }
 
 func (d proxyDriver) Dial(network, address string) (net.Conn, error) {
-	dialer := proxy.FromEnvironment()
-	return dialer.Dial(network, address)
+	return d.DialContext(context.Background(), network, address)
 }
 
 func (d proxyDriver) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
-	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	return proxy.Dial(ctx, network, address)
+	return d.DialContext(ctx, network, address)
+}
+
+func (d proxyDriver) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer, err := d.dialer()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.sshTunnel != nil {
+		return d.sshTunnel.dialContext(ctx, network, address, dialer)
+	}
+	return dialContext(ctx, dialer, network, address)
+}
+
+// dialContext dials with dialer, honoring ctx when the dialer supports it.
+func dialContext(ctx context.Context, dialer proxy.Dialer, network, address string) (net.Conn, error) {
+	if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+		return contextDialer.DialContext(ctx, network, address)
+	}
+	return dialer.Dial(network, address)
+}
+
+func (d proxyDriver) dialer() (proxy.Dialer, error) {
+	if d.proxy == nil {
+		return proxy.FromEnvironment(), nil
+	}
+
+	proxyURL, err := url.Parse(d.proxy.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if d.proxy.Username != "" {
+		proxyURL.User = url.UserPassword(d.proxy.Username, d.proxy.Password)
+	}
+
+	var dialer proxy.Dialer
+	switch proxyURL.Scheme {
+	case "http", "https":
+		dialer = &httpConnectDialer{proxyURL: proxyURL}
+	default:
+		if dialer, err = proxy.FromURL(proxyURL, proxy.Direct); err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+	}
+
+	if d.proxy.NoProxy == "" {
+		return dialer, nil
+	}
+	perHost := proxy.NewPerHost(dialer, proxy.Direct)
+	perHost.AddFromString(d.proxy.NoProxy)
+	return perHost, nil
+}
+
+// httpConnectDialer opens connections through an HTTP proxy with the CONNECT method.
+type httpConnectDialer struct {
+	proxyURL *url.URL
+}
+
+func (h *httpConnectDialer) Dial(network, address string) (net.Conn, error) {
+	return h.DialContext(context.Background(), network, address)
+}
+
+func (h *httpConnectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	proxyAddress := h.proxyURL.Host
+	if h.proxyURL.Port() == "" {
+		port := "80"
+		if h.proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddress = net.JoinHostPort(h.proxyURL.Hostname(), port)
+	}
+
+	var conn net.Conn
+	var err error
+	if h.proxyURL.Scheme == "https" {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: h.proxyURL.Hostname()}}
+		conn, err = dialer.DialContext(ctx, "tcp", proxyAddress)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", proxyAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to proxy %s: %w", proxyAddress, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := h.proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not send CONNECT request to proxy %s: %w", proxyAddress, err)
+	}
+
+	// The server waits for the client to speak first, so nothing follows the response.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not read CONNECT response from proxy %s: %w", proxyAddress, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyAddress, address, resp.Status)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
 }
 
 func init() {
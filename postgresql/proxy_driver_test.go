@@ -0,0 +1,140 @@
+package postgresql
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestListener accepts connections on a local port and passes them to handle.
+func startTestListener(t *testing.T, handle func(net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func startTestHTTPConnectProxy(t *testing.T, requests chan<- *http.Request) string {
+	return startTestListener(t, func(conn net.Conn) {
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		requests <- req
+		if req.Header.Get("Proxy-Authorization") == "" {
+			_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+
+		target, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+			return
+		}
+		defer target.Close()
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() { _, _ = io.Copy(target, conn) }()
+		_, _ = io.Copy(conn, target)
+	})
+}
+
+func TestProxyDriverHTTPConnect(t *testing.T) {
+	target := startTestListener(t, func(conn net.Conn) {
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	})
+	requests := make(chan *http.Request, 2)
+	proxyAddress := startTestHTTPConnectProxy(t, requests)
+
+	driver := proxyDriver{proxy: &ProxyConfig{URL: "http://" + proxyAddress, Username: "user", Password: "secret"}}
+	conn, err := driver.DialTimeout("tcp", target, time.Second)
+	if err != nil {
+		t.Fatalf("could not dial through the proxy: %v", err)
+	}
+	defer conn.Close()
+
+	req := <-requests
+	if req.Method != http.MethodConnect || req.Host != target {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", req.Method+" "+req.Host, "CONNECT "+target)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Fatal("credentials must be sent in Proxy-Authorization, not Authorization")
+	}
+	if auth := req.Header.Get("Proxy-Authorization"); auth != "Basic dXNlcjpzZWNyZXQ=" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", auth, "Basic dXNlcjpzZWNyZXQ=")
+	}
+
+	if _, err := io.WriteString(conn, "ping"); err != nil {
+		t.Fatalf("could not write through the proxy: %v", err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", string(reply), "ping", err)
+	}
+
+	driver = proxyDriver{proxy: &ProxyConfig{URL: "http://" + proxyAddress}}
+	if _, err := driver.Dial("tcp", target); err == nil || !strings.Contains(err.Error(), "407") {
+		t.Fatalf("expected the proxy to refuse the connection, got %v", err)
+	}
+}
+
+func TestProxyDriverNoProxy(t *testing.T) {
+	target := startTestListener(t, func(conn net.Conn) { conn.Close() })
+
+	// The proxy does not exist, so only direct connections succeed.
+	driver := proxyDriver{proxy: &ProxyConfig{URL: "socks5://127.0.0.1:1", NoProxy: "127.0.0.1"}}
+	conn, err := driver.Dial("tcp", target)
+	if err != nil {
+		t.Fatalf("expected a direct connection to %s: %v", target, err)
+	}
+	conn.Close()
+
+	driver = proxyDriver{proxy: &ProxyConfig{URL: "socks5://127.0.0.1:1", NoProxy: "10.0.0.0/8"}}
+	if _, err := driver.Dial("tcp", target); err == nil {
+		t.Fatalf("expected the connection to %s to go through the proxy", target)
+	}
+}
+
+func TestProxyDriverDialTimeout(t *testing.T) {
+	// The proxy accepts connections but never answers the CONNECT request.
+	proxyAddress := startTestListener(t, func(conn net.Conn) {
+		time.Sleep(5 * time.Second)
+		conn.Close()
+	})
+
+	driver := proxyDriver{proxy: &ProxyConfig{URL: "http://" + proxyAddress}}
+	start := time.Now()
+	if _, err := driver.DialTimeout("tcp", "db.example.com:26257", 100*time.Millisecond); err == nil {
+		t.Fatal("expected the dial to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("DialTimeout took %s, the timeout was not honored", elapsed)
+	}
+}
+
+func TestConfigRegistryKeyProxy(t *testing.T) {
+	config := &Config{Host: "localhost", Port: 26257, Username: "root"}
+	direct := config.registryKey("postgres")
+
+	config.Proxy = &ProxyConfig{URL: "socks5://bastion:1080"}
+	if proxied := config.registryKey("postgres"); proxied == direct {
+		t.Fatalf("Config.registryKey must differ between proxies: %#v", proxied)
+	}
+}
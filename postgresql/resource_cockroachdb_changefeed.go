@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -23,15 +24,15 @@ const (
 	CDCCompressionLevel       = "compression_level"
 	CDCKeyColumn              = "key_column"
 	CDCUnordered              = "unordered"
+	CDCDeletionProtection     = "deletion_protection"
 )
 
 func resourceCockroachDBChangefeed() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourceCockroachDBChangefeedCreate),
-		Read:   PGResourceFunc(resourceCockroachDBChangefeedRead),
-		Delete: PGResourceFunc(resourceCockroachDBChangefeedDelete),
-		Update: PGResourceFunc(resourceCockroachDBChangefeedUpdate),
-		Exists: PGResourceExistsFunc(resourceCockroachDBChangefeedExists),
+		CreateContext: PGResourceFunc(resourceCockroachDBChangefeedCreate),
+		ReadContext:   PGResourceFunc(resourceCockroachDBChangefeedRead),
+		DeleteContext: PGResourceFunc(resourceCockroachDBChangefeedDelete),
+		UpdateContext: PGResourceFunc(resourceCockroachDBChangefeedUpdate),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -105,6 +106,12 @@ func resourceCockroachDBChangefeed() *schema.Resource {
 				Description: "Whether the changefeed is unordered. Must be true when key_column is set.",
 				ForceNew:    true,
 			},
+			CDCDeletionProtection: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, Terraform will refuse to cancel this changefeed. Set to false to allow deletion.",
+			},
 		},
 	}
 }
@@ -180,12 +187,14 @@ func resourceCockroachDBChangefeedCreate(db *DBConnection, d *schema.ResourceDat
 }
 
 func resourceCockroachDBChangefeedRead(db *DBConnection, d *schema.ResourceData) error {
-	exists, err := resourceCockroachDBChangefeedExists(db, d)
+	exists, err := jobExists(db, d.Id())
 	if err != nil {
 		return err
 	}
 	if !exists {
-		return resourceCockroachDBChangefeedCreate(db, d)
+		log.Printf("[WARN] CockroachDB changefeed job (%s) not found or no longer running", d.Id())
+		d.SetId("")
+		return nil
 	}
 	return resourceCockroachDBChangefeedReadImpl(db, d)
 }
@@ -193,7 +202,7 @@ func resourceCockroachDBChangefeedRead(db *DBConnection, d *schema.ResourceData)
 func resourceCockroachDBChangefeedReadImpl(db *DBConnection, d *schema.ResourceData) error {
 	jobID := d.Id()
 	var sinkUri, jobTableString, description string
-	err := db.QueryRow(fmt.Sprintf("select sink_uri,topics,description from [show changefeed job %s];", jobID)).Scan(&sinkUri, &jobTableString, &description)
+	err := queryRowScan(db, fmt.Sprintf("select sink_uri,topics,description from [show changefeed job %s];", jobID), nil, &sinkUri, &jobTableString, &description)
 	if err != nil {
 		return fmt.Errorf("Can't retrieve job details: %w", err)
 	}
@@ -238,6 +247,14 @@ func resourceCockroachDBChangefeedReadImpl(db *DBConnection, d *schema.ResourceD
 }
 
 func resourceCockroachDBChangefeedDelete(db *DBConnection, d *schema.ResourceData) error {
+	if d.Get(CDCDeletionProtection).(bool) {
+		return fmt.Errorf(
+			"cannot destroy changefeed job %s: deletion_protection is set to true. "+
+				"Set deletion_protection = false in your configuration before destroying this resource.",
+			d.Id(),
+		)
+	}
+
 	if _, err := db.Exec(fmt.Sprintf("CANCEL JOB %s", d.Id())); err != nil {
 		return fmt.Errorf("could not cancel job: %w", err)
 	}
@@ -245,10 +262,6 @@ func resourceCockroachDBChangefeedDelete(db *DBConnection, d *schema.ResourceDat
 	return nil
 }
 
-func resourceCockroachDBChangefeedExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	return jobExists(db, d.Id())
-}
-
 func resourceCockroachDBChangefeedUpdate(db *DBConnection, d *schema.ResourceData) error {
 	if !d.HasChange(CDCtableList) {
 		return nil
@@ -300,7 +313,7 @@ func jobExists(db QueryAble, jobID string) (bool, error) {
 	var jobIDExists string
 	// Consider changefeed as existing when running or paused so that
 	// Terraform plans update in-place (or drop+create) instead of "object will be created".
-	err := db.QueryRow(fmt.Sprintf("SELECT job_id FROM [SHOW changefeed JOB %s] WHERE status IN ('running', 'paused');", jobID)).Scan(&jobIDExists)
+	err := queryRowScan(db, fmt.Sprintf("SELECT job_id FROM [SHOW changefeed JOB %s] WHERE status IN ('running', 'paused');", jobID), nil, &jobIDExists)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -354,7 +367,7 @@ func waitForJobStatus(db *DBConnection, jobID string, requestedStatus string, ti
 		case <-ticker.C:
 			var status string
 			query := fmt.Sprintf("SELECT status FROM [SHOW JOB %s]", jobID)
-			if err := db.QueryRow(query).Scan(&status); err != nil {
+			if err := queryRowScan(db, query, nil, &status); err != nil {
 				return fmt.Errorf("error querying job status: %w", err)
 			}
 
@@ -2,6 +2,8 @@ package postgresql
 
 import (
 	"fmt"
+	"log"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -12,10 +14,9 @@ const (
 
 func resourceCockroachDBExternalConnection() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourceCockroachDBExternalConnectionCreate),
-		Read:   PGResourceFunc(resourceCockroachDBExternalConnectionRead),
-		Delete: PGResourceFunc(resourceCockroachDBExternalConnectionDelete),
-		Exists: PGResourceExistsFunc(resourceCockroachDBExternalConnectionExists),
+		CreateContext: PGResourceFunc(resourceCockroachDBExternalConnectionCreate),
+		ReadContext:   PGResourceFunc(resourceCockroachDBExternalConnectionRead),
+		DeleteContext: PGResourceFunc(resourceCockroachDBExternalConnectionDelete),
 		Schema: map[string]*schema.Schema{
 			ConnName: {
 				Type:        schema.TypeString,
@@ -48,9 +49,19 @@ func resourceCockroachDBExternalConnectionRead(db *DBConnection, d *schema.Resou
 }
 
 func resourceCockroachDBExternalConnectionReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	exists, err := connExists(db, d.Id())
+	if err != nil {
+		return fmt.Errorf("Error reading EXTERNAL CONNECTION: %w", err)
+	}
+	if !exists {
+		log.Printf("[WARN] CockroachDB EXTERNAL CONNECTION (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	}
+
 	connName := d.Get(ConnName).(string)
 	var connUrl string
-	if err := db.QueryRow(fmt.Sprintf("select connection_uri from [show external connection %s]", connName)).Scan(&connUrl); err != nil {
+	if err := queryRowScan(db, fmt.Sprintf("select connection_uri from [show external connection %s]", connName), nil, &connUrl); err != nil {
 		return fmt.Errorf("Error reading EXTERNAL CONNECTION: %w", err)
 	}
 	d.Set(ConnName, connName)
@@ -66,13 +77,9 @@ func resourceCockroachDBExternalConnectionDelete(db *DBConnection, d *schema.Res
 	return nil
 }
 
-func resourceCockroachDBExternalConnectionExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	return connExists(db, d.Id())
-}
-
 func connExists(db QueryAble, connName string) (bool, error) {
 	var exists bool
-	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM system.external_connections WHERE connection_name = $1);", connName).Scan(&exists); err != nil {
+	if err := queryRowScan(db, "SELECT EXISTS(SELECT 1 FROM system.external_connections WHERE connection_name = $1);", []interface{}{connName}, &exists); err != nil {
 		return false, err
 	}
 	return exists, nil
@@ -25,11 +25,10 @@ const (
 
 func resourcePostgreSQLDatabase() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDatabaseCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDatabaseRead),
-		Update: PGResourceFunc(resourcePostgreSQLDatabaseUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLDatabaseDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLDatabaseExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLDatabaseCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLDatabaseRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLDatabaseUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLDatabaseDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -198,6 +197,7 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error dropping database: %w", err)
 	}
+	db.client.config.closeDatabaseConnections(dbName)
 
 	d.SetId("")
 
@@ -205,10 +205,6 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 	return err
 }
 
-func resourcePostgreSQLDatabaseExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	return dbExists(db, d.Id())
-}
-
 func resourcePostgreSQLDatabaseRead(db *DBConnection, d *schema.ResourceData) error {
 	return resourcePostgreSQLDatabaseReadImpl(db, d)
 }
@@ -216,7 +212,7 @@ func resourcePostgreSQLDatabaseRead(db *DBConnection, d *schema.ResourceData) er
 func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData) error {
 	dbId := d.Id()
 	var dbName, ownerName string
-	err := db.QueryRow("SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba) from pg_database d WHERE datname=$1", dbId).Scan(&dbName, &ownerName)
+	err := queryRowScan(db, "SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba) from pg_database d WHERE datname=$1", []interface{}{dbId}, &dbName, &ownerName)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
@@ -238,13 +234,12 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 
 	dbSQL := fmt.Sprintf(`SELECT %s FROM pg_catalog.pg_database AS d WHERE d.datname = $1`,
 		strings.Join(columns, ", "))
-	err = db.QueryRow(dbSQL, dbId).
-		Scan(
-			&dbEncoding,
-			&dbCollation,
-			&dbCType,
-			&dbConnLimit,
-		)
+	err = queryRowScan(db, dbSQL, []interface{}{dbId},
+		&dbEncoding,
+		&dbCollation,
+		&dbCType,
+		&dbConnLimit,
+	)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
@@ -282,7 +277,7 @@ func resourcePostgreSQLDatabaseUpdate(db *DBConnection, d *schema.ResourceData)
 	return resourcePostgreSQLDatabaseReadImpl(db, d)
 }
 
-func setDBName(db QueryAble, d *schema.ResourceData) error {
+func setDBName(db *DBConnection, d *schema.ResourceData) error {
 	if !d.HasChange(dbNameAttr) {
 		return nil
 	}
@@ -298,6 +293,7 @@ func setDBName(db QueryAble, d *schema.ResourceData) error {
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error updating database name: %w", err)
 	}
+	db.client.config.closeDatabaseConnections(o)
 	d.SetId(n)
 
 	return nil
@@ -14,10 +14,10 @@ import (
 
 func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Update: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
-		Delete: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -34,9 +34,9 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 			},
 			"owner": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				ForceNew:    true,
-				Description: "Target role for which to alter default privileges.",
+				Description: "Target role for which to alter default privileges. When omitted, default privileges are altered for all roles.",
 			},
 			"schema": {
 				Type:        schema.TypeString,
@@ -150,13 +150,28 @@ func generateDefaultPrivilegesID(d *schema.ResourceData) string {
 		pgSchema = "noschema"
 	}
 
+	owner := d.Get("owner").(string)
+	if owner == "" {
+		owner = "allroles"
+	}
+
 	return strings.Join([]string{
 		d.Get("role").(string), d.Get("database").(string), pgSchema,
-		d.Get("owner").(string), d.Get("object_type").(string),
+		owner, d.Get("object_type").(string),
 	}, "_")
 
 }
 
+// defaultPrivilegesTargetClause returns the FOR ROLE / FOR ALL ROLES clause
+// of the ALTER and SHOW DEFAULT PRIVILEGES statements.
+func defaultPrivilegesTargetClause(d *schema.ResourceData) string {
+	owner := d.Get("owner").(string)
+	if owner == "" {
+		return "FOR ALL ROLES"
+	}
+	return fmt.Sprintf("FOR ROLE %s", pq.QuoteIdentifier(owner))
+}
+
 // grantRoleDefaultPrivilegesWithDB grants default privileges outside of a transaction for CockroachDB
 func grantRoleDefaultPrivilegesWithDB(db *DBConnection, d *schema.ResourceData) error {
 	database := d.Get("database").(string)
@@ -174,7 +189,7 @@ func grantRoleDefaultPrivilegesWithDB(db *DBConnection, d *schema.ResourceData)
 	}
 
 	if len(privileges) == 0 {
-		log.Printf("[DEBUG] no default privileges to grant for role %s, owner %s in database: %s,", d.Get("role").(string), d.Get("owner").(string), d.Get("database").(string))
+		log.Printf("[DEBUG] no default privileges to grant for role %s, %s in database: %s,", d.Get("role").(string), defaultPrivilegesTargetClause(d), d.Get("database").(string))
 		return nil
 	}
 
@@ -185,8 +200,8 @@ func grantRoleDefaultPrivilegesWithDB(db *DBConnection, d *schema.ResourceData)
 		inSchema = fmt.Sprintf("IN SCHEMA %s", pq.QuoteIdentifier(pgSchema))
 	}
 
-	query := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s %s GRANT %s ON %sS TO %s",
-		pq.QuoteIdentifier(d.Get("owner").(string)),
+	query := fmt.Sprintf("ALTER DEFAULT PRIVILEGES %s %s GRANT %s ON %sS TO %s",
+		defaultPrivilegesTargetClause(d),
 		inSchema,
 		strings.Join(privileges, ","),
 		strings.ToUpper(d.Get("object_type").(string)),
@@ -220,8 +235,8 @@ func revokeRoleDefaultPrivilegesWithDB(db *DBConnection, d *schema.ResourceData)
 		inSchema = fmt.Sprintf("IN SCHEMA %s", pq.QuoteIdentifier(pgSchema))
 	}
 	query := fmt.Sprintf(
-		"ALTER DEFAULT PRIVILEGES FOR ROLE %s %s REVOKE ALL ON %sS FROM %s",
-		pq.QuoteIdentifier(d.Get("owner").(string)),
+		"ALTER DEFAULT PRIVILEGES %s %s REVOKE ALL ON %sS FROM %s",
+		defaultPrivilegesTargetClause(d),
 		inSchema,
 		strings.ToUpper(d.Get("object_type").(string)),
 		pq.QuoteIdentifier(d.Get("role").(string)),
@@ -241,7 +256,6 @@ func readRoleDefaultPrivilegesWithDB(db *DBConnection, d *schema.ResourceData) e
 	}
 
 	role := d.Get("role").(string)
-	owner := d.Get("owner").(string)
 	pgSchema := d.Get("schema").(string)
 	objectType := d.Get("object_type").(string)
 	privilegesInput := d.Get("privileges").(*schema.Set).List()
@@ -260,10 +274,10 @@ func readRoleDefaultPrivilegesWithDB(db *DBConnection, d *schema.ResourceData) e
 	} else {
 		objectTypeClause = fmt.Sprintf("object_type = '%ss'", objectType)
 	}
-	query = fmt.Sprintf("with a as (show DEFAULT PRIVILEGES for role %s %s) select array_agg(privilege_type) from a where grantee = '%s' and %s;", owner, inSchema, role, objectTypeClause)
+	query = fmt.Sprintf("with a as (show DEFAULT PRIVILEGES %s %s) select array_agg(privilege_type) from a where grantee = %s and %s;", defaultPrivilegesTargetClause(d), inSchema, pq.QuoteLiteral(role), objectTypeClause)
 
 	var privileges pq.ByteaArray
-	if err := db.QueryRow(query).Scan(&privileges); err != nil {
+	if err := queryRowScan(db, query, nil, &privileges); err != nil {
 		return fmt.Errorf("could not read default privileges: %w", err)
 	}
 
@@ -6,9 +6,49 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
+func TestDefaultPrivilegesTargetClause(t *testing.T) {
+	cases := []struct {
+		resource map[string]interface{}
+		expected string
+		id       string
+	}{
+		{
+			resource: map[string]interface{}{
+				"role":        "reader",
+				"database":    "foo",
+				"owner":       "app owner",
+				"object_type": "table",
+			},
+			expected: `FOR ROLE "app owner"`,
+			id:       "reader_foo_noschema_app owner_table",
+		},
+		{
+			resource: map[string]interface{}{
+				"role":        "reader",
+				"database":    "foo",
+				"schema":      "bar",
+				"object_type": "table",
+			},
+			expected: "FOR ALL ROLES",
+			id:       "reader_foo_bar_allroles_table",
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDefaultPrivileges().Schema, c.resource)
+		if out := defaultPrivilegesTargetClause(d); out != c.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
+		}
+		if id := generateDefaultPrivilegesID(d); id != c.id {
+			t.Fatalf("Error matching id and expected: %#v vs %#v", id, c.id)
+		}
+	}
+}
+
 func TestAccPostgresqlDefaultPrivileges(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -273,6 +313,56 @@ resource "postgresql_default_privileges" "test_ro" {
 	}
 }
 
+func TestAccPostgresqlDefaultPrivileges_AllRoles(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// No owner: default privileges apply to objects created by any role.
+	var tfConfig = fmt.Sprintf(`
+resource "postgresql_default_privileges" "test_ro" {
+	database    = "%s"
+	role        = "%s"
+	object_type = "table"
+	privileges  = %%s
+}
+`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						tables := []string{"test_schema.test_table", "dev_schema.test_table"}
+						dropFunc := createTestTables(t, dbSuffix, tables, "")
+						defer dropFunc()
+
+						return testCheckTablesPrivileges(t, dbName, roleName, tables, []string{"SELECT"})
+					},
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "id", fmt.Sprintf("%s_%s_noschema_allroles_table", roleName, dbName)),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "privileges.0", "SELECT"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, `["SELECT", "UPDATE"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_ro", "privileges.#", "2"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlDefaultPrivileges_Sequence(t *testing.T) {
 	skipIfNotAcc(t)
 
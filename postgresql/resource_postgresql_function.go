@@ -13,17 +13,18 @@ import (
 )
 
 const (
-	funcNameAttr            = "name"
-	funcSchemaAttr          = "schema"
-	funcBodyAttr            = "body"
-	funcArgAttr             = "arg"
-	funcLanguageAttr        = "language"
-	funcReturnsAttr         = "returns"
-	funcDropCascadeAttr     = "drop_cascade"
-	funcDatabaseAttr        = "database"
-	funcSecurityDefinerAttr = "security_definer"
-	funcStrictAttr          = "strict"
-	funcVolatilityAttr      = "volatility"
+	funcNameAttr               = "name"
+	funcSchemaAttr             = "schema"
+	funcBodyAttr               = "body"
+	funcArgAttr                = "arg"
+	funcLanguageAttr           = "language"
+	funcReturnsAttr            = "returns"
+	funcDropCascadeAttr        = "drop_cascade"
+	funcDatabaseAttr           = "database"
+	funcSecurityDefinerAttr    = "security_definer"
+	funcStrictAttr             = "strict"
+	funcVolatilityAttr         = "volatility"
+	funcDeletionProtectionAttr = "deletion_protection"
 
 	funcArgTypeAttr    = "type"
 	funcArgNameAttr    = "name"
@@ -35,11 +36,10 @@ const (
 
 func resourcePostgreSQLFunction() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLFunctionCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLFunctionRead),
-		Update: PGResourceFunc(resourcePostgreSQLFunctionUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLFunctionDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLFunctionExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLFunctionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLFunctionRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLFunctionUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLFunctionDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -156,6 +156,12 @@ func resourcePostgreSQLFunction() *schema.Resource {
 				Optional:    true,
 				Default:     false,
 			},
+			funcDeletionProtectionAttr: {
+				Type:        schema.TypeBool,
+				Description: "If true, Terraform will refuse to destroy this function. Set to false to allow deletion.",
+				Optional:    true,
+				Default:     false,
+			},
 			funcSecurityDefinerAttr: {
 				Type:        schema.TypeBool,
 				Description: "If the function should execute with the permissions of the function owner instead of the permissions of the caller.",
@@ -204,37 +210,6 @@ func resourcePostgreSQLFunctionCreate(db *DBConnection, d *schema.ResourceData)
 	return resourcePostgreSQLFunctionReadImpl(db, d)
 }
 
-func resourcePostgreSQLFunctionExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	if !db.featureSupported(featureFunction) {
-		return false, fmt.Errorf(
-			"postgresql_function resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
-	}
-
-	functionId := d.Id()
-
-	databaseName, functionSignature, expandErr := expandFunctionID(functionId, d, db)
-	if expandErr != nil {
-		return false, expandErr
-	}
-
-	var functionExists bool
-
-	dbConn, err := connectToDatabase(db, databaseName)
-	if err != nil {
-		return false, err
-	}
-
-	query := fmt.Sprintf("SELECT to_regprocedure('%s') IS NOT NULL AS functionExists", functionSignature)
-
-	if err := dbConn.QueryRow(query).Scan(&functionExists); err != nil {
-		return false, err
-	}
-
-	return functionExists, nil
-}
-
 func resourcePostgreSQLFunctionRead(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featureFunction) {
 		return fmt.Errorf(
@@ -275,7 +250,7 @@ func resourcePostgreSQLFunctionReadImpl(db *DBConnection, d *schema.ResourceData
 		return err
 	}
 
-	err = dbConn.QueryRow(query, functionSignature).Scan(&funcDefinition)
+	err = queryRowScan(dbConn, query, []interface{}{functionSignature}, &funcDefinition)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL function: %s", functionId)
@@ -327,6 +302,14 @@ func resourcePostgreSQLFunctionDelete(db *DBConnection, d *schema.ResourceData)
 		)
 	}
 
+	if d.Get(funcDeletionProtectionAttr).(bool) {
+		return fmt.Errorf(
+			"cannot destroy function %q: deletion_protection is set to true. "+
+				"Set deletion_protection = false in your configuration before destroying this resource.",
+			d.Get(funcNameAttr).(string),
+		)
+	}
+
 	databaseName, functionSignature, err := expandFunctionID(d.Id(), d, db)
 	if err != nil {
 		return err
@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -309,7 +310,52 @@ resource "postgresql_function" "import_func" {
 				ImportState:       true,
 				ImportStateVerify: true,
 				// body may have whitespace differences; drop_cascade is local-only.
-				ImportStateVerifyIgnore: []string{"body", "drop_cascade"},
+				ImportStateVerifyIgnore: []string{"body", "drop_cascade", "deletion_protection"},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlFunction_DeletionProtection(t *testing.T) {
+	config := `
+resource "postgresql_function" "protected_func" {
+  name                = "protected_func"
+  returns             = "integer"
+  language            = "plpgsql"
+  deletion_protection = %t
+  body = <<-EOF
+    BEGIN
+      RETURN 1;
+    END;
+  EOF
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureFunction)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlFunctionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlFunctionExists("postgresql_function.protected_func", ""),
+					resource.TestCheckResourceAttr("postgresql_function.protected_func", "deletion_protection", "true"),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(config, true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`deletion_protection is set to true`),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_function.protected_func", "deletion_protection", "false"),
+				),
 			},
 		},
 	})
@@ -1,6 +1,7 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -27,19 +28,30 @@ var allowedObjectTypes = []string{
 
 func resourcePostgreSQLGrant() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLGrantCreate),
-		// Since all of this resource's arguments force a recreation
-		// there's no need for an Update function
-		// Update:
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLGrantCreate),
+		// Update re-applies the grant, either because privileges changed or
+		// because some objects drifted (e.g. tables created after the grant).
+		UpdateContext: PGResourceFunc(resourcePostgreSQLGrantUpdate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLGrantRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLGrantDelete),
+
+		CustomizeDiff: resourcePostgreSQLGrantCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"role": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The name of the role to grant privileges on",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"role", "roles"},
+				Description:  "The name of the role to grant privileges on",
+			},
+			"roles": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Set:          schema.HashString,
+				ExactlyOneOf: []string{"role", "roles"},
+				Description:  "The names of the roles to grant privileges on, in a single statement. Grantees can be added or removed in place",
 			},
 			"database": {
 				Type:        schema.TypeString,
@@ -71,11 +83,16 @@ func resourcePostgreSQLGrant() *schema.Resource {
 			"privileges": {
 				Type:        schema.TypeSet,
 				Required:    true,
-				ForceNew:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
 				Description: "The list of privileges to grant",
 			},
+			"object_privileges": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The effective privileges of the role(s) on each object, as a comma-separated sorted list keyed by object name",
+			},
 			"with_grant_option": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -83,13 +100,19 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Default:     false,
 				Description: "Permit the grant recipient to grant it to others",
 			},
+			"validate_catalog": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Check at plan time that the role(s), schema and objects exist and that the privileges are supported by the connected CockroachDB version. Disabled by default as the plan fails when they are created in the same apply",
+			},
 		},
 	}
 }
 
 func resourcePostgreSQLGrantRead(db *DBConnection, d *schema.ResourceData) error {
 	if err := validateFeatureSupport(db, d); err != nil {
-		return fmt.Errorf("feature is not supported: %v", err)
+		return fmt.Errorf("feature is not supported: %w", err)
 	}
 
 	exists, err := checkRoleDBSchemaExists(db, d)
@@ -127,7 +150,13 @@ func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) err
 	}
 
 	if err := validateFeatureSupport(db, d); err != nil {
-		return fmt.Errorf("feature is not supported: %v", err)
+		return fmt.Errorf("feature is not supported: %w", err)
+	}
+	if err := validatePrivilegesSupported(db, d.Get("privileges").(*schema.Set).List()); err != nil {
+		return err
+	}
+	if err := checkGranteesExist(db, d); err != nil {
+		return err
 	}
 
 	database := d.Get("database").(string)
@@ -150,9 +179,88 @@ func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) err
 	return readRolePrivileges(dbConn, d)
 }
 
+func resourcePostgreSQLGrantUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if err := validatePrivileges(d); err != nil {
+		return err
+	}
+
+	if err := validateFeatureSupport(db, d); err != nil {
+		return fmt.Errorf("feature is not supported: %w", err)
+	}
+	if err := validatePrivilegesSupported(db, d.Get("privileges").(*schema.Set).List()); err != nil {
+		return err
+	}
+	if err := checkGranteesExist(db, d); err != nil {
+		return err
+	}
+
+	database := d.Get("database").(string)
+
+	dbConn, err := connectToDatabase(db, database)
+	if err != nil {
+		return err
+	}
+
+	oldRoles, newRoles := d.GetChange("roles")
+	oldPrivileges, newPrivileges := d.GetChange("privileges")
+	removedGrantees := setToSortedList(oldRoles.(*schema.Set).Difference(newRoles.(*schema.Set)))
+	addedGrantees := newRoles.(*schema.Set).Difference(oldRoles.(*schema.Set))
+
+	var keptGrantees []string
+	for _, grantee := range getGrantees(d) {
+		if !addedGrantees.Contains(grantee) {
+			keptGrantees = append(keptGrantees, grantee)
+		}
+	}
+
+	// The statements run in a single transaction so that the grantees never lose
+	// the privileges kept by the configuration, even when a statement fails.
+	err = withTransaction(dbConn, func(_ *DBConnection, txn QueryAble) error {
+		// Grantees removed from roles lose the privileges previously granted to them.
+		if err := revokePrivilegesFromGrantees(txn, d, oldPrivileges.(*schema.Set), removedGrantees); err != nil {
+			return err
+		}
+
+		if err := grantPrivilegesToGrantees(txn, d, setToSortedList(newPrivileges.(*schema.Set)), setToSortedList(addedGrantees)); err != nil {
+			return err
+		}
+
+		switch {
+		case d.HasChange("privileges"):
+			// Only the privileges removed from the configuration are revoked, and the added ones granted.
+			revoked := setToSortedList(oldPrivileges.(*schema.Set).Difference(newPrivileges.(*schema.Set)))
+			if err := revokeSpecificPrivilegesFromGrantees(txn, d, revoked, keptGrantees); err != nil {
+				return err
+			}
+			granted := newPrivileges.(*schema.Set).Difference(oldPrivileges.(*schema.Set))
+			if isAllObjectsGrant(d) {
+				// Grants on all the objects of a schema are applied again for the objects created since.
+				granted = newPrivileges.(*schema.Set)
+			}
+			return grantPrivilegesToGrantees(txn, d, setToSortedList(granted), keptGrantees)
+
+		case d.HasChange("object_privileges"):
+			// Some objects drifted: the grant is applied again on every object, after revoking
+			// the privileges which were granted outside of the configuration.
+			if err := revokePrivilegesFromGrantees(txn, d, newPrivileges.(*schema.Set), keptGrantees); err != nil {
+				return err
+			}
+			return grantPrivilegesToGrantees(txn, d, setToSortedList(newPrivileges.(*schema.Set)), keptGrantees)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(generateGrantID(d))
+
+	return readRolePrivileges(dbConn, d)
+}
+
 func resourcePostgreSQLGrantDelete(db *DBConnection, d *schema.ResourceData) error {
 	if err := validateFeatureSupport(db, d); err != nil {
-		return fmt.Errorf("feature is not supported: %v", err)
+		return fmt.Errorf("feature is not supported: %w", err)
 	}
 
 	database := d.Get("database").(string)
@@ -172,38 +280,37 @@ func readSystemRolePriviges(db QueryAble, role string) error {
 	var query string
 	var privileges pq.ByteaArray
 	query = fmt.Sprintf(`with a as (show system grants for %s) select array_agg(privilege_type) from a`, role)
-	if err := db.QueryRow(query).Scan(&privileges); err != nil {
+	if err := queryRowScan(db, query, nil, &privileges); err != nil {
 		return fmt.Errorf("could not read system privileges: %w", err)
 	}
 	return nil
 }
 
-func readDatabaseRolePriviges(db QueryAble, d *schema.ResourceData, role string) error {
+func readDatabaseRolePriviges(db QueryAble, d *schema.ResourceData, role string) (*schema.Set, error) {
 	dbName := d.Get("database").(string)
 	var privileges pq.ByteaArray
 	query := fmt.Sprintf(`with a as (show grants on database %s for %s) select array_agg(privilege_type) from a where grantee=%s`, pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(role), pq.QuoteLiteral(role))
-	if err := db.QueryRow(query).Scan(&privileges); err != nil {
-		return fmt.Errorf("could not read privileges for database %s: %w", dbName, err)
+	if err := queryRowScan(db, query, nil, &privileges); err != nil {
+		return nil, fmt.Errorf("could not read privileges for database %s: %w", dbName, err)
 	}
 
-	d.Set("privileges", pgArrayToSet(privileges))
-	return nil
+	return pgArrayToSet(privileges), nil
 }
 
-func readSchemaRolePriviges(db QueryAble, d *schema.ResourceData, role string) error {
+func readSchemaRolePriviges(db QueryAble, d *schema.ResourceData, role string) (*schema.Set, error) {
 	schemaName := d.Get("schema").(string)
 	var privileges pq.ByteaArray
 	query := fmt.Sprintf(`with a as ( show grants on schema %s for %s) select array_agg(privilege_type) from a where grantee=%s;`, pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(role), pq.QuoteLiteral(role))
-	if err := db.QueryRow(query).Scan(&privileges); err != nil {
-		return fmt.Errorf("could not read privileges for schema %s: %w", schemaName, err)
+	if err := queryRowScan(db, query, nil, &privileges); err != nil {
+		return nil, fmt.Errorf("could not read privileges for schema %s: %w", schemaName, err)
 	}
 
-	d.Set("privileges", pgArrayToSet(privileges))
-	return nil
+	return pgArrayToSet(privileges), nil
 }
 
-func readRolePrivileges(db QueryAble, d *schema.ResourceData) error {
-	role := d.Get("role").(string)
+// readGranteePrivileges returns the privileges of role on each object targeted
+// by the resource, keyed by object name.
+func readGranteePrivileges(db QueryAble, d *schema.ResourceData, role string) (map[string]*schema.Set, error) {
 	objectType := d.Get("object_type").(string)
 	objects := d.Get("objects").(*schema.Set)
 
@@ -213,12 +320,21 @@ func readRolePrivileges(db QueryAble, d *schema.ResourceData) error {
 
 	switch objectType {
 	case "system":
-		return readSystemRolePriviges(db, role)
+		return nil, readSystemRolePriviges(db, role)
+
 	case "database":
-		return readDatabaseRolePriviges(db, d, role)
+		privileges, err := readDatabaseRolePriviges(db, d, role)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*schema.Set{d.Get("database").(string): privileges}, nil
 
 	case "schema":
-		return readSchemaRolePriviges(db, d, role)
+		privileges, err := readSchemaRolePriviges(db, d, role)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*schema.Set{d.Get("schema").(string): privileges}, nil
 
 	case "function", "procedure", "routine":
 		// CockroachDB: pg_proc.proacl is always NULL; use information_schema instead
@@ -232,71 +348,314 @@ GROUP BY routine_name`,
 		rows, err = db.Query(query)
 
 	default:
-		query = fmt.Sprintf("with a as (show tables from %s) , b as (show grants on table * for %s) select a.table_name,  array_agg(privilege_type) from a inner join b on a.table_name=b.table_name and a.schema_name = b.schema_name  where a.type='%s'  and grantee= %s group by a.table_name;", pq.QuoteIdentifier(d.Get("schema").(string)), pq.QuoteIdentifier(role), objectType, pq.QuoteLiteral(role))
+		// LEFT JOIN so that objects on which the role has no privilege at all
+		// (e.g. tables created after the grant) are reported too.
+		query = fmt.Sprintf("with a as (show tables from %s) , b as (show grants on table * for %s) select a.table_name, array_remove(array_agg(b.privilege_type), NULL) from a left join b on a.table_name=b.table_name and a.schema_name = b.schema_name and b.grantee = %s where a.type='%s' group by a.table_name;", pq.QuoteIdentifier(d.Get("schema").(string)), pq.QuoteIdentifier(role), pq.QuoteLiteral(role), objectType)
 		rows, err = db.Query(query)
 	}
 
 	// This returns, for the specified role (rolname),
 	// the list of all object of the specified type in the specified schema
 	// with the list of the currently applied privileges (aggregation of privilege_type)
-	//
-	// Our goal is to check that every object has the same privileges as saved in the state.
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
+	objectPrivileges := map[string]*schema.Set{}
 	for rows.Next() {
 		var objName string
 		var privileges pq.ByteaArray
 
 		if err := rows.Scan(&objName, &privileges); err != nil {
-			return err
+			return nil, err
 		}
 
 		if objects.Len() > 0 && !objects.Contains(objName) {
 			continue
 		}
 
-		privilegesSet := pgArrayToSet(privileges)
+		objectPrivileges[objName] = pgArrayToSet(privileges)
+	}
+
+	return objectPrivileges, rows.Err()
+}
+
+// readRolePrivileges reads the privileges of every grantee of the resource.
+// Our goal is to check that every object has the same privileges as saved in the state.
+func readRolePrivileges(db QueryAble, d *schema.ResourceData) error {
+	objectType := d.Get("object_type").(string)
+	objects := d.Get("objects").(*schema.Set)
+	multipleRoles := d.Get("roles").(*schema.Set).Len() > 0
+
+	expectedPrivileges := d.Get("privileges").(*schema.Set)
+	expected := setToSortedString(expectedPrivileges)
+
+	// When the grant targets all objects of the schema, the drift is reported
+	// per object through object_privileges and reconciled by CustomizeDiff.
+	// Otherwise, we return the privileges of the first drifted object to force an update.
+	reportPrivilegesDrift := objects.Len() > 0 || objectType == "database" || objectType == "schema"
+
+	objectPrivileges := map[string]interface{}{}
+	privilegesDrifted := false
+	grantedRoles := []interface{}{}
+
+	if err := checkGranteesExist(db, d); err != nil {
+		return err
+	}
+
+	for _, role := range getGrantees(d) {
+		rolePrivileges, err := readGranteePrivileges(db, d, role)
+		if err != nil {
+			return err
+		}
+
+		hasPrivileges := false
+		for objName, privilegesSet := range rolePrivileges {
+			actual := setToSortedString(privilegesSet)
+			if privilegesSet.Len() > 0 {
+				hasPrivileges = true
+			}
+
+			// With several grantees, an object reports the privileges of the first grantee which doesn't match.
+			if current, ok := objectPrivileges[objName]; !ok || current.(string) == expected {
+				objectPrivileges[objName] = actual
+			}
+
+			if actual == expected {
+				continue
+			}
 
-		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) {
-			// If any object doesn't have the same privileges as saved in the state,
-			// we return its privileges to force an update.
 			log.Printf(
 				"[DEBUG] %s %s has not the expected privileges %v for role %s",
-				strings.ToTitle(objectType), objName, privileges, d.Get("role"),
+				strings.ToTitle(objectType), objName, actual, role,
 			)
-			d.Set("privileges", privilegesSet)
-			break
+
+			if reportPrivilegesDrift && !privilegesDrifted {
+				d.Set("privileges", privilegesSet)
+				privilegesDrifted = true
+			}
 		}
+
+		// A grantee which lost all its privileges is removed from roles so the plan grants it again.
+		if !hasPrivileges && expectedPrivileges.Len() > 0 && len(rolePrivileges) > 0 {
+			log.Printf("[DEBUG] role %s has no privileges left", role)
+			continue
+		}
+		grantedRoles = append(grantedRoles, role)
+	}
+
+	d.Set("object_privileges", objectPrivileges)
+	if multipleRoles {
+		d.Set("roles", schema.NewSet(schema.HashString, grantedRoles))
 	}
 
 	return nil
 }
 
+// resourcePostgreSQLGrantCustomizeDiff plans an update of object_privileges when
+// a grant on all objects of a schema no longer matches every object, so that
+// applying re-runs the GRANT ... ON ALL ... IN SCHEMA statement.
+func resourcePostgreSQLGrantCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := validateGrantDiff(ctx, d, meta); err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	if d.HasChange("privileges") {
+		return d.SetNewComputed("object_privileges")
+	}
+
+	if d.Get("objects").(*schema.Set).Len() > 0 {
+		return nil
+	}
+
+	current := d.Get("object_privileges").(map[string]interface{})
+	desired, drifted := reconcileObjectPrivileges(current, d.Get("privileges").(*schema.Set))
+	if !drifted {
+		return nil
+	}
+
+	return d.SetNew("object_privileges", desired)
+}
+
+// validateGrantDiff validates the grant at plan time: the privileges against the object type
+// and, when validate_catalog is set, the grantees, schema and objects against the live catalog.
+func validateGrantDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"object_type", "objects", "privileges"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	objectType := d.Get("object_type").(string)
+	objects := d.Get("objects").(*schema.Set).List()
+	privileges := d.Get("privileges").(*schema.Set).List()
+
+	if err := validateObjectTypePrivileges(objectType, privileges); err != nil {
+		return err
+	}
+
+	if !d.Get("validate_catalog").(bool) || meta == nil {
+		return nil
+	}
+	for _, key := range []string{"role", "roles", "database", "schema"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	conn, err := meta.(*Client).ConnectContext(ctx)
+	if err != nil {
+		return err
+	}
+	// The catalog queries are cancelled with the plan.
+	db := conn.withContext(ctx, nil)
+
+	if err := validatePrivilegesSupported(db, privileges); err != nil {
+		return err
+	}
+
+	grantees := d.Get("roles").(*schema.Set).List()
+	if role := d.Get("role").(string); role != "" {
+		grantees = append(grantees, role)
+	}
+	for _, grantee := range grantees {
+		if grantee.(string) == publicRole {
+			continue
+		}
+		exists, err := roleExists(db, grantee.(string))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("role %s does not exist", grantee)
+		}
+	}
+
+	database := d.Get("database").(string)
+	exists, err := dbExists(db, database)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("database %s does not exist", database)
+	}
+
+	pgSchema := d.Get("schema").(string)
+	if pgSchema == "" || objectType == "database" || objectType == "system" {
+		return nil
+	}
+
+	dbConn, err := connectToDatabase(db, database)
+	if err != nil {
+		return err
+	}
+
+	exists, err = schemaExists(dbConn, pgSchema)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("schema %s does not exist in database %s", pgSchema, database)
+	}
+
+	for _, object := range objects {
+		exists, err := objectExists(dbConn, objectType, pgSchema, object.(string))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s %s does not exist in schema %s", objectType, object, pgSchema)
+		}
+	}
+
+	return nil
+}
+
+// reconcileObjectPrivileges returns the expected object_privileges map for the given
+// privileges and whether any object currently differs from it.
+func reconcileObjectPrivileges(current map[string]interface{}, privileges *schema.Set) (map[string]interface{}, bool) {
+	expected := setToSortedString(privileges)
+	desired := make(map[string]interface{}, len(current))
+	drifted := false
+
+	for objName, objPrivileges := range current {
+		desired[objName] = expected
+		if objPrivileges.(string) != expected {
+			drifted = true
+		}
+	}
+
+	return desired, drifted
+}
+
+// isAllObjectsGrant returns true if the privileges are granted on all the objects of a schema.
+func isAllObjectsGrant(d *schema.ResourceData) bool {
+	switch strings.ToUpper(d.Get("object_type").(string)) {
+	case "TABLE", "SEQUENCE", "FUNCTION", "PROCEDURE", "ROUTINE":
+		return d.Get("objects").(*schema.Set).Len() == 0
+	}
+	return false
+}
+
+// setToSortedString returns the elements of a string set sorted and joined with commas.
+func setToSortedString(set *schema.Set) string {
+	return strings.Join(setToSortedList(set), ",")
+}
+
+// getGrantees returns the roles the privileges are granted to: the roles set
+// when specified, the single role otherwise.
+func getGrantees(d *schema.ResourceData) []string {
+	if roles := d.Get("roles").(*schema.Set); roles.Len() > 0 {
+		return setToSortedList(roles)
+	}
+	if role := d.Get("role").(string); role != "" {
+		return []string{role}
+	}
+	return nil
+}
+
+func granteesToPgIdentList(grantees []string) string {
+	quoted := make([]string, len(grantees))
+	for i, grantee := range grantees {
+		quoted[i] = pq.QuoteIdentifier(grantee)
+	}
+	return strings.Join(quoted, ",")
+}
+
 func createGrantQuery(d *schema.ResourceData, privileges []string) string {
+	return createGrantQueryForGrantees(d, privileges, getGrantees(d))
+}
+
+// createGrantQueryForGrantees builds the grant query of the resource for the given grantees.
+func createGrantQueryForGrantees(d *schema.ResourceData, privileges []string, grantees []string) string {
 	var query string
+	to := granteesToPgIdentList(grantees)
 
 	switch strings.ToUpper(d.Get("object_type").(string)) {
 	case "SYSTEM":
 		query = fmt.Sprintf(
 			"GRANT SYSTEM %s TO %s",
 			strings.Join(privileges, ","),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			to,
 		)
 	case "DATABASE":
 		query = fmt.Sprintf(
 			"GRANT %s ON DATABASE %s TO %s",
 			strings.Join(privileges, ","),
 			pq.QuoteIdentifier(d.Get("database").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			to,
 		)
 	case "SCHEMA":
 		query = fmt.Sprintf(
 			"GRANT %s ON SCHEMA %s TO %s",
 			strings.Join(privileges, ","),
 			pq.QuoteIdentifier(d.Get("schema").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			to,
 		)
 	case "TABLE", "SEQUENCE", "FUNCTION", "PROCEDURE", "ROUTINE":
 		objects := d.Get("objects").(*schema.Set)
@@ -306,7 +665,7 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
 				setToPgIdentList(d.Get("schema").(string), objects),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				to,
 			)
 		} else {
 			query = fmt.Sprintf(
@@ -314,7 +673,7 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
 				pq.QuoteIdentifier(d.Get("schema").(string)),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				to,
 			)
 		}
 	}
@@ -327,29 +686,35 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 }
 
 func createRevokeQuery(d *schema.ResourceData) string {
+	return createRevokeQueryForGrantees(d, d.Get("privileges").(*schema.Set), getGrantees(d))
+}
+
+// createRevokeQueryForGrantees builds the revoke query of the resource for the given grantees,
+// revoking the given privileges when the grant targets specific objects.
+func createRevokeQueryForGrantees(d *schema.ResourceData, privileges *schema.Set, grantees []string) string {
 	var query string
+	from := granteesToPgIdentList(grantees)
 
 	switch strings.ToUpper(d.Get("object_type").(string)) {
 	case "SYSTEM":
 		query = fmt.Sprintf(
 			"REVOKE SYSTEM ALL FROM %s",
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			from,
 		)
 	case "DATABASE":
 		query = fmt.Sprintf(
 			"REVOKE ALL PRIVILEGES ON DATABASE %s FROM %s",
 			pq.QuoteIdentifier(d.Get("database").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			from,
 		)
 	case "SCHEMA":
 		query = fmt.Sprintf(
 			"REVOKE ALL PRIVILEGES ON SCHEMA %s FROM %s",
 			pq.QuoteIdentifier(d.Get("schema").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			from,
 		)
 	case "TABLE", "SEQUENCE", "FUNCTION", "PROCEDURE", "ROUTINE":
 		objects := d.Get("objects").(*schema.Set)
-		privileges := d.Get("privileges").(*schema.Set)
 		if objects.Len() > 0 {
 			if privileges.Len() > 0 {
 				// Revoking specific privileges instead of all privileges
@@ -359,14 +724,14 @@ func createRevokeQuery(d *schema.ResourceData) string {
 					setToPgIdentSimpleList(privileges),
 					strings.ToUpper(d.Get("object_type").(string)),
 					setToPgIdentList(d.Get("schema").(string), objects),
-					pq.QuoteIdentifier(d.Get("role").(string)),
+					from,
 				)
 			} else {
 				query = fmt.Sprintf(
 					"REVOKE ALL PRIVILEGES ON %s %s FROM %s",
 					strings.ToUpper(d.Get("object_type").(string)),
 					setToPgIdentList(d.Get("schema").(string), objects),
-					pq.QuoteIdentifier(d.Get("role").(string)),
+					from,
 				)
 			}
 		} else {
@@ -374,7 +739,57 @@ func createRevokeQuery(d *schema.ResourceData) string {
 				"REVOKE ALL PRIVILEGES ON ALL %sS IN SCHEMA %s FROM %s",
 				strings.ToUpper(d.Get("object_type").(string)),
 				pq.QuoteIdentifier(d.Get("schema").(string)),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				from,
+			)
+		}
+	}
+
+	return query
+}
+
+// createRevokePrivilegesQuery builds a query revoking the given privileges from the given grantees.
+func createRevokePrivilegesQuery(d *schema.ResourceData, privileges []string, grantees []string) string {
+	var query string
+	from := granteesToPgIdentList(grantees)
+
+	switch strings.ToUpper(d.Get("object_type").(string)) {
+	case "SYSTEM":
+		query = fmt.Sprintf(
+			"REVOKE SYSTEM %s FROM %s",
+			strings.Join(privileges, ","),
+			from,
+		)
+	case "DATABASE":
+		query = fmt.Sprintf(
+			"REVOKE %s ON DATABASE %s FROM %s",
+			strings.Join(privileges, ","),
+			pq.QuoteIdentifier(d.Get("database").(string)),
+			from,
+		)
+	case "SCHEMA":
+		query = fmt.Sprintf(
+			"REVOKE %s ON SCHEMA %s FROM %s",
+			strings.Join(privileges, ","),
+			pq.QuoteIdentifier(d.Get("schema").(string)),
+			from,
+		)
+	case "TABLE", "SEQUENCE", "FUNCTION", "PROCEDURE", "ROUTINE":
+		objects := d.Get("objects").(*schema.Set)
+		if objects.Len() > 0 {
+			query = fmt.Sprintf(
+				"REVOKE %s ON %s %s FROM %s",
+				strings.Join(privileges, ","),
+				strings.ToUpper(d.Get("object_type").(string)),
+				setToPgIdentList(d.Get("schema").(string), objects),
+				from,
+			)
+		} else {
+			query = fmt.Sprintf(
+				"REVOKE %s ON ALL %sS IN SCHEMA %s FROM %s",
+				strings.Join(privileges, ","),
+				strings.ToUpper(d.Get("object_type").(string)),
+				pq.QuoteIdentifier(d.Get("schema").(string)),
+				from,
 			)
 		}
 	}
@@ -384,17 +799,21 @@ func createRevokeQuery(d *schema.ResourceData) string {
 
 // grantRolePrivilegesWithDB grants privileges using the DB connection directly
 func grantRolePrivilegesWithDB(db *DBConnection, d *schema.ResourceData) error {
-	privileges := []string{}
-	for _, priv := range d.Get("privileges").(*schema.Set).List() {
-		privileges = append(privileges, priv.(string))
+	return grantPrivilegesToGrantees(db, d, setToSortedList(d.Get("privileges").(*schema.Set)), getGrantees(d))
+}
+
+// grantPrivilegesToGrantees grants the given privileges to the given grantees in a single statement
+func grantPrivilegesToGrantees(db QueryAble, d *schema.ResourceData, privileges []string, grantees []string) error {
+	if len(grantees) == 0 {
+		return nil
 	}
 
 	if len(privileges) == 0 {
-		log.Printf("[DEBUG] no privileges to grant for role %s in database: %s,", d.Get("role").(string), d.Get("database"))
+		log.Printf("[DEBUG] no privileges to grant for roles %v in database: %s,", grantees, d.Get("database"))
 		return nil
 	}
 
-	query := createGrantQuery(d, privileges)
+	query := createGrantQueryForGrantees(d, privileges, grantees)
 
 	_, err := db.Exec(query)
 	return err
@@ -402,7 +821,16 @@ func grantRolePrivilegesWithDB(db *DBConnection, d *schema.ResourceData) error {
 
 // revokeRolePrivilegesWithDB revokes privileges using the DB connection directly
 func revokeRolePrivilegesWithDB(db *DBConnection, d *schema.ResourceData) error {
-	query := createRevokeQuery(d)
+	return revokePrivilegesFromGrantees(db, d, d.Get("privileges").(*schema.Set), getGrantees(d))
+}
+
+// revokePrivilegesFromGrantees revokes the given privileges from the given grantees in a single statement
+func revokePrivilegesFromGrantees(db QueryAble, d *schema.ResourceData, privileges *schema.Set, grantees []string) error {
+	if len(grantees) == 0 {
+		return nil
+	}
+
+	query := createRevokeQueryForGrantees(d, privileges, grantees)
 	if len(query) == 0 {
 		// Query is empty, don't run anything
 		return nil
@@ -413,10 +841,42 @@ func revokeRolePrivilegesWithDB(db *DBConnection, d *schema.ResourceData) error
 	return nil
 }
 
+// revokeSpecificPrivilegesFromGrantees revokes only the given privileges from the given grantees,
+// whatever the object type, leaving their other privileges untouched.
+func revokeSpecificPrivilegesFromGrantees(db QueryAble, d *schema.ResourceData, privileges []string, grantees []string) error {
+	if len(grantees) == 0 || len(privileges) == 0 {
+		return nil
+	}
+
+	query := createRevokePrivilegesQuery(d, privileges, grantees)
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("could not execute revoke query: %w", err)
+	}
+	return nil
+}
+
+// checkGranteesExist returns an error naming the first role of roles which doesn't exist,
+// instead of silently skipping it and planning its grant again at every run.
+func checkGranteesExist(db QueryAble, d *schema.ResourceData) error {
+	for _, role := range setToSortedList(d.Get("roles").(*schema.Set)) {
+		if role == publicRole {
+			continue
+		}
+		exists, err := roleExists(db, role)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return newAttributeError("roles", fmt.Errorf("role %s does not exist", role))
+		}
+	}
+	return nil
+}
+
 func checkRoleDBSchemaExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	// Check the role exists
+	// Check the role exists (with multiple roles, each grantee is checked when reading)
 	role := d.Get("role").(string)
-	if role != publicRole {
+	if role != "" && role != publicRole {
 		exists, err := roleExists(db, role)
 		if err != nil {
 			return false, err
@@ -462,7 +922,7 @@ func checkRoleDBSchemaExists(db *DBConnection, d *schema.ResourceData) (bool, er
 }
 
 func generateGrantID(d *schema.ResourceData) string {
-	parts := []string{d.Get("role").(string), d.Get("database").(string)}
+	parts := []string{strings.Join(getGrantees(d), ","), d.Get("database").(string)}
 
 	objectType := d.Get("object_type").(string)
 	if objectType != "database" {
@@ -485,22 +945,22 @@ func validateFeatureSupport(db *DBConnection, d *schema.ResourceData) error {
 		)
 	}
 	if d.Get("object_type") == "procedure" && !db.featureSupported(featureProcedure) {
-		return fmt.Errorf(
+		return newAttributeError("object_type", fmt.Errorf(
 			"object type PROCEDURE is not supported for this version (%s)",
 			db.version,
-		)
+		))
 	}
 	if d.Get("object_type") == "routine" && !db.featureSupported(featureRoutine) {
-		return fmt.Errorf(
+		return newAttributeError("object_type", fmt.Errorf(
 			"object type ROUTINE is not supported for this version (%s)",
 			db.version,
-		)
+		))
 	}
 	if d.Get("object_type") == "system" && !db.featureSupported(featureSysPrivileges) {
-		return fmt.Errorf(
+		return newAttributeError("object_type", fmt.Errorf(
 			"privilege type System is not supported for this version (%s)",
 			db.version,
-		)
+		))
 	}
 	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -13,9 +12,13 @@ import (
 
 func resourcePostgreSQLGrantRole() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLGrantRoleUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -33,7 +36,6 @@ func resourcePostgreSQLGrantRole() *schema.Resource {
 			"with_admin_option": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     false,
 				Description: "Permit the grant recipient to grant it to others",
 			},
@@ -89,31 +91,121 @@ func resourcePostgreSQLGrantRoleDelete(db *DBConnection, d *schema.ResourceData)
 	return nil
 }
 
-func readGrantRole(db *DBConnection, d *schema.ResourceData) error {
-	var roleName, grantRoleName string
-	var withAdminOption bool
+func resourcePostgreSQLGrantRoleUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return fmt.Errorf(
+			"postgresql_grant_role resource is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	if d.HasChange("with_admin_option") {
+		if err := setRoleAdminOption(db, d.Get("grant_role").(string), d.Get("role").(string), d.Get("with_admin_option").(bool)); err != nil {
+			return err
+		}
+	}
+
+	return readGrantRole(db, d)
+}
 
-	values := []interface{}{
-		&roleName,
-		&grantRoleName,
-		&withAdminOption,
+func readGrantRole(db *DBConnection, d *schema.ResourceData) error {
+	// On import only the ID is known: find the membership it was generated from.
+	if d.Get("role").(string) == "" {
+		role, grantRole, err := resolveGrantRoleID(db, d.Id())
+		if err != nil {
+			return err
+		}
+		d.Set("role", role)
+		d.Set("grant_role", grantRole)
 	}
 
-	query := fmt.Sprintf(` with a as (show grants on role %s for %s) select member as role , role_name as grant_role, is_admin as with_admin_option from a;
-`, pq.QuoteIdentifier(d.Get("grant_role").(string)), pq.QuoteIdentifier(d.Get("role").(string)))
-	err := db.QueryRow(query).Scan(values...)
+	role := d.Get("role").(string)
+	grantRole := d.Get("grant_role").(string)
+
+	var withAdminOption bool
+	err := queryRowScan(db,
+		"SELECT admin_option FROM pg_catalog.pg_auth_members WHERE pg_get_userbyid(roleid) = $1 AND pg_get_userbyid(member) = $2",
+		[]interface{}{grantRole, role}, &withAdminOption,
+	)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL grant role %s for %s not found, removing from state", d.Get("grant_role"), d.Get("role"))
+		log.Printf("[WARN] PostgreSQL grant role %s for %s not found, removing from state", grantRole, role)
 		d.SetId("")
 		return nil
 	case err != nil:
-		return fmt.Errorf("Error to show grants on role %s for %s :%w ", d.Get("grant_role"), d.Get("role"), err)
+		return fmt.Errorf("could not read grant of role %s to %s: %w", grantRole, role, err)
 	}
 
+	d.Set("with_admin_option", withAdminOption)
+
 	return nil
 }
 
+// resolveGrantRoleID returns the member and granted role of the membership whose
+// ID is *id*. Role names may contain underscores, so every way of splitting the ID is
+// looked up and the first existing membership is returned.
+func resolveGrantRoleID(db QueryAble, id string) (string, string, error) {
+	candidates := grantRoleIDCandidates(id)
+
+	roles := make([]string, len(candidates))
+	grantRoles := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		roles[i], grantRoles[i] = candidate[0], candidate[1]
+	}
+
+	rows, err := db.Query(
+		`SELECT pg_get_userbyid(member), pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members
+		WHERE pg_get_userbyid(member) = ANY($1) AND pg_get_userbyid(roleid) = ANY($2)`,
+		pq.Array(roles), pq.Array(grantRoles),
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("could not read role memberships: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := make(map[[2]string]bool)
+	for rows.Next() {
+		var membership [2]string
+		if err := rows.Scan(&membership[0], &membership[1]); err != nil {
+			return "", "", fmt.Errorf("could not scan role membership: %w", err)
+		}
+		memberships[membership] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", err
+	}
+
+	for _, candidate := range candidates {
+		if memberships[candidate] {
+			return candidate[0], candidate[1], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no role membership matches ID %q, expected <role>_<grant_role>", id)
+}
+
+// grantRoleIDCandidates returns the (role, grant_role) pairs an ID can be split into, in order
+// of preference. IDs generated before the admin option could be updated in place end with
+// _<with_admin_option>, they are still accepted.
+func grantRoleIDCandidates(id string) [][2]string {
+	ids := []string{id}
+	for _, suffix := range []string{"_true", "_false"} {
+		if trimmed, ok := strings.CutSuffix(id, suffix); ok {
+			ids = append(ids, trimmed)
+		}
+	}
+
+	var candidates [][2]string
+	for _, candidateID := range ids {
+		for i := range candidateID {
+			if candidateID[i] == '_' {
+				candidates = append(candidates, [2]string{candidateID[:i], candidateID[i+1:]})
+			}
+		}
+	}
+	return candidates
+}
+
 func createGrantRoleQuery(d *schema.ResourceData) string {
 	grantRole, _ := d.Get("grant_role").(string)
 	role, _ := d.Get("role").(string)
@@ -163,5 +255,5 @@ func revokeRoleWithDB(db *DBConnection, d *schema.ResourceData) error {
 }
 
 func generateGrantRoleID(d *schema.ResourceData) string {
-	return strings.Join([]string{d.Get("role").(string), d.Get("grant_role").(string), strconv.FormatBool(d.Get("with_admin_option").(bool))}, "_")
+	return strings.Join([]string{d.Get("role").(string), d.Get("grant_role").(string)}, "_")
 }
@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -105,16 +106,16 @@ func TestAccPostgresqlGrantRole(t *testing.T) {
 
 	grantedRoleName := "foo"
 
-	testAccPostgresqlGrantRoleResources := fmt.Sprintf(`
+	testAccPostgresqlGrantRoleResources := `
 	resource postgresql_role "grant" {
 		name = "%s"
 	}
 	resource postgresql_grant_role "grant_role" {
 		role              = "%s"
 		grant_role        = postgresql_role.grant.name
-		with_admin_option = true
+		with_admin_option = %t
 	}
-	`, grantedRoleName, roleName)
+	`
 
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -124,7 +125,7 @@ func TestAccPostgresqlGrantRole(t *testing.T) {
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccPostgresqlGrantRoleResources,
+				Config: fmt.Sprintf(testAccPostgresqlGrantRoleResources, grantedRoleName, roleName, true),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(
 						"postgresql_grant_role.grant_role", "role", roleName),
@@ -135,10 +136,58 @@ func TestAccPostgresqlGrantRole(t *testing.T) {
 					checkGrantRole(t, dsn, roleName, grantedRoleName, true),
 				),
 			},
+			{
+				// The admin option is revoked in place.
+				Config: fmt.Sprintf(testAccPostgresqlGrantRoleResources, grantedRoleName, roleName, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"postgresql_grant_role.grant_role", "with_admin_option", strconv.FormatBool(false)),
+					checkGrantRole(t, dsn, roleName, grantedRoleName, false),
+				),
+			},
+			{
+				// The admin option granted outside of Terraform is detected and revoked.
+				PreConfig: func() {
+					dbExecute(t, dsn, fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", grantedRoleName, roleName))
+				},
+				Config: fmt.Sprintf(testAccPostgresqlGrantRoleResources, grantedRoleName, roleName, false),
+				Check:  checkGrantRole(t, dsn, roleName, grantedRoleName, false),
+			},
+			{
+				ResourceName:      "postgresql_grant_role.grant_role",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
 
+func TestGenerateGrantRoleID(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrantRole().Schema, map[string]interface{}{
+		"role":              "app_user",
+		"grant_role":        "readers",
+		"with_admin_option": true,
+	})
+
+	expected := "app_user_readers"
+	if out := generateGrantRoleID(d); out != expected {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, expected)
+	}
+}
+
+func TestGrantRoleIDCandidates(t *testing.T) {
+	expected := [][2]string{{"app", "user_readers"}, {"app_user", "readers"}}
+	if out := grantRoleIDCandidates("app_user_readers"); !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, expected)
+	}
+
+	// Legacy IDs ending with the admin option are split after the current format.
+	expected = [][2]string{{"app", "readers_true"}, {"app_readers", "true"}, {"app", "readers"}}
+	if out := grantRoleIDCandidates("app_readers_true"); !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, expected)
+	}
+}
+
 func checkGrantRole(t *testing.T, dsn, role string, grantRole string, withAdmin bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		db, err := sql.Open("postgres", dsn)
@@ -2,10 +2,12 @@ package postgresql
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -115,6 +117,24 @@ func TestCreateGrantQuery(t *testing.T) {
 			privileges: []string{"SELECT"},
 			expected:   fmt.Sprintf(`GRANT SELECT ON TABLE %[1]s."o2",%[1]s."o1" TO %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
 		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "table",
+				"schema":      databaseName,
+				"roles":       []interface{}{"r2", "r1"},
+			}),
+			privileges: []string{"SELECT"},
+			expected:   fmt.Sprintf(`GRANT SELECT ON ALL TABLES IN SCHEMA %s TO "r1","r2"`, pq.QuoteIdentifier(databaseName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "database",
+				"database":    databaseName,
+				"roles":       []interface{}{"r1", "r2"},
+			}),
+			privileges: []string{"CONNECT"},
+			expected:   fmt.Sprintf(`GRANT CONNECT ON DATABASE %s TO "r1","r2"`, pq.QuoteIdentifier(databaseName)),
+		},
 	}
 
 	for _, c := range cases {
@@ -209,6 +229,14 @@ func TestCreateRevokeQuery(t *testing.T) {
 			}),
 			expected: fmt.Sprintf(`REVOKE UPDATE,INSERT ON TABLE %[1]s."o2",%[1]s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
 		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "schema",
+				"schema":      databaseName,
+				"roles":       []interface{}{"r2", "r1"},
+			}),
+			expected: fmt.Sprintf(`REVOKE ALL PRIVILEGES ON SCHEMA %s FROM "r1","r2"`, pq.QuoteIdentifier(databaseName)),
+		},
 	}
 
 	for _, c := range cases {
@@ -219,6 +247,115 @@ func TestCreateRevokeQuery(t *testing.T) {
 	}
 }
 
+func TestCreateRevokePrivilegesQuery(t *testing.T) {
+	var databaseName = "foo"
+	var roleName = "bar"
+	var tableObjects = []interface{}{"o1", "o2"}
+
+	cases := []struct {
+		resource   *schema.ResourceData
+		privileges []string
+		expected   string
+	}{
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "system",
+				"role":        roleName,
+			}),
+			privileges: []string{"VIEWACTIVITY"},
+			expected:   fmt.Sprintf("REVOKE SYSTEM VIEWACTIVITY FROM %s", pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "database",
+				"database":    databaseName,
+				"role":        roleName,
+			}),
+			privileges: []string{"CONNECT", "CREATE"},
+			expected:   fmt.Sprintf("REVOKE CONNECT,CREATE ON DATABASE %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "schema",
+				"schema":      databaseName,
+				"roles":       []interface{}{"r2", "r1"},
+			}),
+			privileges: []string{"USAGE"},
+			expected:   fmt.Sprintf(`REVOKE USAGE ON SCHEMA %s FROM "r1","r2"`, pq.QuoteIdentifier(databaseName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "table",
+				"objects":     tableObjects,
+				"schema":      databaseName,
+				"role":        roleName,
+			}),
+			privileges: []string{"INSERT"},
+			expected:   fmt.Sprintf(`REVOKE INSERT ON TABLE %[1]s."o2",%[1]s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "sequence",
+				"schema":      databaseName,
+				"role":        roleName,
+			}),
+			privileges: []string{"USAGE"},
+			expected:   fmt.Sprintf("REVOKE USAGE ON ALL SEQUENCES IN SCHEMA %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+	}
+
+	for _, c := range cases {
+		out := createRevokePrivilegesQuery(c.resource, c.privileges, getGrantees(c.resource))
+		if out != c.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
+		}
+	}
+}
+
+func TestReconcileObjectPrivileges(t *testing.T) {
+	cases := []struct {
+		current    map[string]interface{}
+		privileges []interface{}
+		desired    map[string]interface{}
+		drifted    bool
+	}{
+		{
+			current:    map[string]interface{}{"t1": "INSERT,SELECT", "t2": "INSERT,SELECT"},
+			privileges: []interface{}{"SELECT", "INSERT"},
+			desired:    map[string]interface{}{"t1": "INSERT,SELECT", "t2": "INSERT,SELECT"},
+			drifted:    false,
+		},
+		{
+			current:    map[string]interface{}{"t1": "SELECT", "new_table": ""},
+			privileges: []interface{}{"SELECT"},
+			desired:    map[string]interface{}{"t1": "SELECT", "new_table": "SELECT"},
+			drifted:    true,
+		},
+		{
+			current:    map[string]interface{}{"t1": "DELETE,SELECT"},
+			privileges: []interface{}{"SELECT"},
+			desired:    map[string]interface{}{"t1": "SELECT"},
+			drifted:    true,
+		},
+		{
+			current:    map[string]interface{}{},
+			privileges: []interface{}{"SELECT"},
+			desired:    map[string]interface{}{},
+			drifted:    false,
+		},
+	}
+
+	for _, c := range cases {
+		desired, drifted := reconcileObjectPrivileges(c.current, schema.NewSet(schema.HashString, c.privileges))
+		if drifted != c.drifted {
+			t.Fatalf("reconcileObjectPrivileges(%v, %v) drifted = %v, want %v", c.current, c.privileges, drifted, c.drifted)
+		}
+		if !reflect.DeepEqual(desired, c.desired) {
+			t.Fatalf("reconcileObjectPrivileges(%v, %v) = %#v, want %#v", c.current, c.privileges, desired, c.desired)
+		}
+	}
+}
+
 func TestAccPostgresqlGrant(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -397,6 +534,138 @@ func TestAccPostgresqlGrantObjects(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlGrantNewObjects(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = ["SELECT"]
+	}
+	`, dbName, roleName)
+
+	newTables := []string{"test_schema.test_table_new"}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "object_privileges.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "object_privileges.test_table", "SELECT"),
+					func(*terraform.State) error {
+						// Create a table after the grant: it doesn't have the privileges yet.
+						createTestTables(t, dbSuffix, newTables, "")
+						return nil
+					},
+				),
+			},
+			{
+				// The new table is reported as drifted and the grant is re-applied in place.
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.0", "SELECT"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "object_privileges.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "object_privileges.test_table_new", "SELECT"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, append(testTables, newTables...), []string{"SELECT"})
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantMultipleRoles(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := getTestConfig(t)
+	role2 := fmt.Sprintf("tf_tests_role2_%s", dbSuffix)
+	createTestRole(t, role2)
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("GRANT usage ON SCHEMA test_schema to %s", role2))
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		roles       = %%s
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = ["SELECT"]
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, fmt.Sprintf(`["%s"]`, roleName)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "roles.#", "1"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, role2, testTables, []string{})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, fmt.Sprintf(`["%s", "%s"]`, roleName, role2)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "roles.#", "2"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, role2, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, fmt.Sprintf(`["%s"]`, role2)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "roles.#", "1"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, role2, testTables, []string{"SELECT"})
+					},
+				),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlGrantObjectsError(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -432,6 +701,31 @@ func TestAccPostgresqlGrantObjectsError(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlGrantMissingRole(t *testing.T) {
+	skipIfNotAcc(t)
+
+	missingRole := acctest.RandomWithPrefix("test_missing_role")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`resource "postgresql_grant" "test" {
+					database    = "postgres"
+					roles       = ["public", %q]
+					object_type = "database"
+					privileges  = ["CONNECT"]
+				}`, missingRole),
+				ExpectError: regexp.MustCompile(fmt.Sprintf("role %s does not exist", missingRole)),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlGrantPublic(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -4,10 +4,14 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/lib/pq"
@@ -21,6 +25,9 @@ const (
 	roleLoginAttr                           = "login"
 	roleNameAttr                            = "name"
 	rolePasswordAttr                        = "password"
+	rolePasswordWOAttr                      = "password_wo"
+	rolePasswordVersionAttr                 = "password_version"
+	rolePasswordHashAttr                    = "password_hash"
 	roleSkipDropRoleAttr                    = "skip_drop_role"
 	roleSkipReassignOwnedAttr               = "skip_reassign_owned"
 	roleValidUntilAttr                      = "valid_until"
@@ -29,15 +36,85 @@ const (
 	roleStatementTimeoutAttr                = "statement_timeout"
 	defaultTransactionIsolationAttr         = "default_transaction_isolation"
 	defaultTransactionFollowerReadsAttr     = "default_transaction_use_follower_reads"
+	roleSubjectAttr                         = "subject"
+	roleControlJobAttr                      = "control_job"
+	roleControlChangefeedAttr               = "control_changefeed"
+	roleViewActivityAttr                    = "view_activity"
+	roleCancelQueryAttr                     = "cancel_query"
+	roleModifyClusterSettingAttr            = "modify_cluster_setting"
+	roleCreateLoginAttr                     = "create_login"
+	roleNoSQLLoginAttr                      = "no_sql_login"
+	roleReplicationAttr                     = "replication"
+	roleSessionDefaultsAttr                 = "session_defaults"
+	roleDatabaseSessionDefaultsAttr         = "database_session_defaults"
+	roleReassignOwnedToAttr                 = "reassign_owned_to"
+	roleIncludeDatabasesAttr                = "include_databases"
+	roleExcludeDatabasesAttr                = "exclude_databases"
+	roleDeletionDryRunAttr                  = "deletion_dry_run"
+	roleDeletionParallelismAttr             = "deletion_parallelism"
+	roleDeletionProtectionAttr              = "deletion_protection"
+	roleDeletionGuardAttr                   = "deletion_guard"
 )
 
+// roleOwnedObjectsQuery lists the objects owned by a role in the current database.
+const roleOwnedObjectsQuery = `
+	WITH r AS (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+	SELECT 'database', datname FROM pg_catalog.pg_database, r WHERE datdba = r.oid AND datname = current_database()
+	UNION ALL
+	SELECT 'schema', nspname FROM pg_catalog.pg_namespace, r WHERE nspowner = r.oid
+	UNION ALL
+	SELECT CASE c.relkind WHEN 'S' THEN 'sequence' WHEN 'v' THEN 'view' WHEN 'm' THEN 'materialized view' ELSE 'table' END,
+		n.nspname || '.' || c.relname
+	FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace, r
+	WHERE c.relowner = r.oid AND c.relkind IN ('r', 'v', 'm', 'S')
+	UNION ALL
+	SELECT 'function', n.nspname || '.' || p.proname
+	FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace, r
+	WHERE p.proowner = r.oid
+	UNION ALL
+	SELECT 'type', n.nspname || '.' || t.typname
+	FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace, r
+	WHERE t.typowner = r.oid AND t.typtype = 'e'
+	ORDER BY 1, 2
+	`
+
+// roleManagedSettings are the session variables managed by dedicated role attributes,
+// which are excluded from session_defaults.
+var roleManagedSettings = []string{
+	roleSearchPathAttr,
+	roleStatementTimeoutAttr,
+	roleIdleInTransactionSessionTimeoutAttr,
+	defaultTransactionIsolationAttr,
+	defaultTransactionFollowerReadsAttr,
+}
+
+var sessionVariableRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// roleBoolOption is a boolean CockroachDB role option, available from the version of feature.
+type roleBoolOption struct {
+	hclKey        string
+	sqlKeyEnable  string
+	sqlKeyDisable string
+	feature       featureName
+}
+
+var cockroachdbRoleBoolOptions = []roleBoolOption{
+	{roleControlJobAttr, "CONTROLJOB", "NOCONTROLJOB", featureRoleControlJob},
+	{roleControlChangefeedAttr, "CONTROLCHANGEFEED", "NOCONTROLCHANGEFEED", featureRoleControlChangefeed},
+	{roleViewActivityAttr, "VIEWACTIVITY", "NOVIEWACTIVITY", featureRoleViewActivity},
+	{roleCancelQueryAttr, "CANCELQUERY", "NOCANCELQUERY", featureRoleCancelQuery},
+	{roleModifyClusterSettingAttr, "MODIFYCLUSTERSETTING", "NOMODIFYCLUSTERSETTING", featureRoleModifyClusterSetting},
+	{roleCreateLoginAttr, "CREATELOGIN", "NOCREATELOGIN", featureRoleCreateLogin},
+	{roleNoSQLLoginAttr, "NOSQLLOGIN", "SQLLOGIN", featureRoleNoSQLLogin},
+	{roleReplicationAttr, "REPLICATION", "NOREPLICATION", featureRoleReplication},
+}
+
 func resourcePostgreSQLRole() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLRoleRead),
-		Update: PGResourceFunc(resourcePostgreSQLRoleUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLRoleDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLRoleExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLRoleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLRoleRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLRoleUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLRoleDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -50,10 +127,33 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Description: "The name of the role. Renaming a role forces it to be destroyed and recreated, which drops all associated grants.",
 			},
 			rolePasswordAttr: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Sensitive:   true,
-				Description: "Sets the role's password",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{rolePasswordWOAttr, rolePasswordHashAttr},
+				Description:   "Sets the role's password",
+			},
+			rolePasswordWOAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				WriteOnly:     true,
+				Sensitive:     true,
+				ConflictsWith: []string{rolePasswordAttr, rolePasswordHashAttr},
+				Description:   "Sets the role's password without storing it in the state. It is only sent on creation and when `password_version` changes",
+			},
+			rolePasswordVersionAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{rolePasswordWOAttr},
+				Description:  "Version of `password_wo`. Change it to rotate the write-only password",
+			},
+			rolePasswordHashAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{rolePasswordAttr, rolePasswordWOAttr},
+				ValidateFunc:  validateScramVerifier,
+				Description:   "Sets the role's password from a precomputed SCRAM-SHA-256 verifier (`SCRAM-SHA-256$<iterations>:<salt>$<stored key>:<server key>`) so the plaintext password never reaches Terraform",
 			},
 			roleRolesAttr: {
 				Type:        schema.TypeSet,
@@ -118,6 +218,50 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Default:     false,
 				Description: "Skip actually running the REASSIGN OWNED command when removing a role from PostgreSQL",
 			},
+			roleReassignOwnedToAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role which receives the objects owned by this role when it is removed. Defaults to the provider user",
+			},
+			roleIncludeDatabasesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Only run REASSIGN OWNED and DROP OWNED in these databases when removing the role. Defaults to all non-system databases",
+			},
+			roleExcludeDatabasesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Don't run REASSIGN OWNED and DROP OWNED in these databases when removing the role",
+			},
+			roleDeletionDryRunAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail the removal of the role with the list of objects which would be reassigned and privileges which would be dropped, without changing anything",
+			},
+			roleDeletionParallelismAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The number of databases in which REASSIGN OWNED and DROP OWNED run concurrently when removing the role",
+			},
+			roleDeletionProtectionAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, Terraform will refuse to destroy this role. Set to false to allow deletion.",
+			},
+			roleDeletionGuardAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, Terraform will refuse to destroy this role while it has active sessions or owns objects in any database, including the ones excluded from the cleanup",
+			},
 			roleStatementTimeoutAttr: {
 				Type:         schema.TypeInt,
 				Optional:     true,
@@ -134,6 +278,89 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Optional:    true,
 				Description: "Role default_transaction_use_follower_reads",
 			},
+			roleSessionDefaultsAttr: {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				ValidateFunc:     validateRoleSessionDefaults,
+				DiffSuppressFunc: sessionDefaultDiffSuppressFunc,
+				Description:      "Default values of session variables for the role, applied with ALTER ROLE ... SET. Variables managed by dedicated attributes (e.g. `search_path`) can't be set here",
+			},
+			roleDatabaseSessionDefaultsAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Set:      databaseSessionDefaultsHash,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"database": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The database the session defaults apply to",
+						},
+						"settings": {
+							Type:         schema.TypeMap,
+							Required:     true,
+							Elem:         &schema.Schema{Type: schema.TypeString},
+							ValidateFunc: validateSessionVariableNames,
+							Description:  "Default values of session variables for the role in this database",
+						},
+					},
+				},
+				Description: "Default values of session variables for the role in a specific database, applied with ALTER ROLE ... IN DATABASE ... SET",
+			},
+			roleSubjectAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The distinguished name of the client certificate subject mapped to this role (e.g. `CN=my_role,O=my_org`)",
+			},
+			roleControlJobAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can pause, resume and cancel jobs",
+			},
+			roleControlChangefeedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can run CREATE CHANGEFEED on tables it has SELECT privileges on",
+			},
+			roleViewActivityAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can see the queries and sessions of other users",
+			},
+			roleCancelQueryAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can cancel the queries and sessions of other users",
+			},
+			roleModifyClusterSettingAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can modify cluster settings",
+			},
+			roleCreateLoginAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can create, alter and drop the login options of other roles",
+			},
+			roleNoSQLLoginAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Prevent a role from logging in with the SQL shell while still allowing DB Console logins",
+			},
+			roleReplicationAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Determine whether a role can run physical cluster replication streams",
+			},
 		},
 	}
 }
@@ -163,6 +390,16 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 
 	if db.featureSupported(featureRLS) {
 		boolOpts = append(boolOpts, boolOptType{roleBypassRLSAttr, "BYPASSRLS", "NOBYPASSRLS"})
+	} else if d.Get(roleBypassRLSAttr).(bool) {
+		warnRoleBypassRLSUnsupported(db)
+	}
+
+	for _, opt := range cockroachdbRoleBoolOptions {
+		if db.featureSupported(opt.feature) {
+			boolOpts = append(boolOpts, boolOptType{opt.hclKey, opt.sqlKeyEnable, opt.sqlKeyDisable})
+		} else if d.Get(opt.hclKey).(bool) {
+			return newAttributeError(opt.hclKey, fmt.Errorf("role option %s is not supported for this version (%s)", opt.sqlKeyEnable, db.version))
+		}
 	}
 
 	createOpts := make([]string, 0, len(stringOpts)+len(intOpts)+len(boolOpts))
@@ -196,6 +433,21 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		}
 	}
 
+	// password, password_wo and password_hash conflict with each other so at most one PASSWORD option is set.
+	if password := readWriteOnlyPassword(d); password != "" {
+		createOpts = append(createOpts, fmt.Sprintf("PASSWORD '%s'", pqQuoteLiteral(password)))
+	}
+	if passwordHash := d.Get(rolePasswordHashAttr).(string); passwordHash != "" {
+		createOpts = append(createOpts, fmt.Sprintf("PASSWORD '%s'", pqQuoteLiteral(passwordHash)))
+	}
+
+	if subject := d.Get(roleSubjectAttr).(string); subject != "" {
+		if !db.featureSupported(featureRoleSubject) {
+			return newAttributeError(roleSubjectAttr, fmt.Errorf("role option SUBJECT is not supported for this version (%s)", db.version))
+		}
+		createOpts = append(createOpts, fmt.Sprintf("SUBJECT '%s'", pqQuoteLiteral(subject)))
+	}
+
 	for _, opt := range intOpts {
 		val := d.Get(opt.hclKey).(int)
 		createOpts = append(createOpts, fmt.Sprintf("%s %d", opt.sqlKey, val))
@@ -217,36 +469,22 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 	}
 
 	sqlStr := fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr)
-	if _, err := db.Exec(sqlStr); err != nil {
-		return fmt.Errorf("error creating role %s: %w", roleName, err)
-	}
-
-	if err := grantRoles(db, d); err != nil {
-		return err
-	}
 
-	if err := alterSearchPath(db, d); err != nil {
-		return err
-	}
-
-	if err := setStatementTimeout(db, d); err != nil {
-		return err
-	}
-
-	if err := setIdleInTransactionSessionTimeout(db, d); err != nil {
-		return err
-	}
-
-	if db.featureSupported(featureTransactionIsolation) {
-		if err := setDefaultTransactionIsolation(db, d); err != nil {
-			return err
+	// The role and its memberships and settings are created atomically, so a failure
+	// doesn't leave behind a half-configured role which isn't in the state.
+	err := withTransaction(db, func(db *DBConnection, txn QueryAble) error {
+		if _, err := txn.Exec(sqlStr); err != nil {
+			return fmt.Errorf("error creating role %s: %w", roleName, err)
 		}
-	}
 
-	if db.featureSupported(featureFollowerReads) {
-		if err := setDefaultFollowerReads(db, d); err != nil {
+		if err := grantRoles(txn, d); err != nil {
 			return err
 		}
+
+		return setRoleSettings(db, txn, d)
+	})
+	if err != nil {
+		return err
 	}
 
 	d.SetId(roleName)
@@ -257,27 +495,65 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 func resourcePostgreSQLRoleDelete(db *DBConnection, d *schema.ResourceData) error {
 	roleName := d.Get(roleNameAttr).(string)
 
-	if !d.Get(roleSkipReassignOwnedAttr).(bool) {
-		currentUser := db.client.config.getDatabaseUsername()
+	if d.Get(roleDeletionProtectionAttr).(bool) {
+		return fmt.Errorf(
+			"cannot destroy role %q: deletion_protection is set to true. "+
+				"Set deletion_protection = false in your configuration before destroying this resource.",
+			roleName,
+		)
+	}
 
-		// REASSIGN OWNED BY and DROP OWNED BY are database-scoped, so we need
-		// to run them in every database where the role might own objects.
-		databases, err := getDatabases(db)
+	// REASSIGN OWNED BY and DROP OWNED BY are database-scoped, so we need
+	// to run them in every database where the role might own objects.
+	var allDatabases, databases []string
+	if !d.Get(roleSkipReassignOwnedAttr).(bool) || d.Get(roleDeletionGuardAttr).(bool) {
+		var err error
+		allDatabases, err = getDatabases(db)
 		if err != nil {
 			return fmt.Errorf("could not list databases for role %s cleanup: %w", roleName, err)
 		}
+		databases = filterDatabases(
+			allDatabases,
+			setToSortedList(d.Get(roleIncludeDatabasesAttr).(*schema.Set)),
+			setToSortedList(d.Get(roleExcludeDatabasesAttr).(*schema.Set)),
+		)
+	}
+	parallelism := d.Get(roleDeletionParallelismAttr).(int)
 
-		for _, database := range databases {
-			dbConn, err := connectToDatabase(db, database)
-			if err != nil {
-				return fmt.Errorf("could not connect to database %s to reassign owned by role %s: %w", database, roleName, err)
-			}
-			if _, err := dbConn.Exec(fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(currentUser))); err != nil {
-				return fmt.Errorf("could not reassign owned by role %s to %s in database %s: %w", roleName, currentUser, database, err)
+	reassignOwnedTo := d.Get(roleReassignOwnedToAttr).(string)
+	if reassignOwnedTo == "" {
+		reassignOwnedTo = db.client.config.getDatabaseUsername()
+	}
+
+	// The dry run is checked before anything is changed, whichever steps are skipped.
+	if d.Get(roleDeletionDryRunAttr).(bool) {
+		var reassignDatabases []string
+		if !d.Get(roleSkipReassignOwnedAttr).(bool) {
+			reassignDatabases = databases
+		}
+		return describeRoleDeletion(db, roleName, reassignOwnedTo, reassignDatabases, parallelism, !d.Get(roleSkipDropRoleAttr).(bool))
+	}
+
+	// The guard checks every database, not only the ones cleaned up, since objects the role
+	// owns in an excluded database would make DROP ROLE fail anyway.
+	if d.Get(roleDeletionGuardAttr).(bool) {
+		if err := checkRoleNotInUse(db, roleName, allDatabases, parallelism); err != nil {
+			return err
+		}
+	}
+
+	if !d.Get(roleSkipReassignOwnedAttr).(bool) {
+		err := forEachDatabase(db, databases, parallelism, func(dbConn *DBConnection, database string) error {
+			if _, err := dbConn.Exec(fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(reassignOwnedTo))); err != nil {
+				return fmt.Errorf("could not reassign owned by role %s to %s in database %s: %w", roleName, reassignOwnedTo, database, err)
 			}
 			if _, err := dbConn.Exec(fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName))); err != nil {
 				return fmt.Errorf("could not drop owned by role %s in database %s: %w", roleName, database, err)
 			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 	if !d.Get(roleSkipDropRoleAttr).(bool) {
@@ -291,17 +567,122 @@ func resourcePostgreSQLRoleDelete(db *DBConnection, d *schema.ResourceData) erro
 	return nil
 }
 
-func resourcePostgreSQLRoleExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	var roleName string
-	err := db.QueryRow("SELECT rolname FROM pg_catalog.pg_roles WHERE rolname=$1", d.Id()).Scan(&roleName)
-	switch {
-	case err == sql.ErrNoRows:
-		return false, nil
-	case err != nil:
-		return false, err
+// checkRoleNotInUse returns an error if the role has active sessions in the cluster
+// or owns objects in one of the databases. It fails closed: a database whose objects
+// can't be listed is an error rather than being skipped.
+func checkRoleNotInUse(db *DBConnection, roleName string, databases []string, parallelism int) error {
+	var sessions int
+	if err := queryRowScan(db, "SELECT count(*) FROM crdb_internal.cluster_sessions WHERE user_name = $1", []interface{}{roleName}, &sessions); err != nil {
+		return fmt.Errorf("could not count active sessions of role %s: %w", roleName, err)
+	}
+	if sessions > 0 {
+		return fmt.Errorf("cannot destroy role %q: deletion_guard is set to true and the role has %d active session(s)", roleName, sessions)
+	}
+
+	var lock sync.Mutex
+	var ownedIn []string
+	err := forEachDatabase(db, databases, parallelism, func(dbConn *DBConnection, database string) error {
+		objects, err := readRoleOwnedObjects(dbConn, roleName)
+		if err != nil {
+			return fmt.Errorf("could not list objects owned by role %s in database %s: %w", roleName, database, err)
+		}
+		if len(objects) > 0 {
+			lock.Lock()
+			defer lock.Unlock()
+			ownedIn = append(ownedIn, database)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(ownedIn) > 0 {
+		sort.Strings(ownedIn)
+		return fmt.Errorf(
+			"cannot destroy role %q: deletion_guard is set to true and the role owns objects in database(s) %s",
+			roleName, strings.Join(ownedIn, ", "),
+		)
 	}
 
-	return true, nil
+	return nil
+}
+
+// describeRoleDeletion returns an error listing, for each database, the objects which would be
+// reassigned and the privileges which would be dropped when removing the role, then the role itself.
+func describeRoleDeletion(db *DBConnection, roleName, reassignOwnedTo string, databases []string, parallelism int, dropRole bool) error {
+	var lock sync.Mutex
+	changes := make(map[string][]string, len(databases))
+
+	err := forEachDatabase(db, databases, parallelism, func(dbConn *DBConnection, database string) error {
+		objects, err := readRoleOwnedObjects(dbConn, roleName)
+		if err != nil {
+			return fmt.Errorf("could not list objects owned by role %s in database %s: %w", roleName, database, err)
+		}
+
+		var databaseChanges []string
+		for _, object := range objects {
+			databaseChanges = append(databaseChanges, fmt.Sprintf("reassign %s to %s", object, reassignOwnedTo))
+		}
+
+		privileges, err := readEffectiveDatabasePrivileges(dbConn, database, roleName, []string{roleName})
+		if err != nil {
+			return err
+		}
+		for _, privilege := range privileges {
+			p := privilege.(map[string]interface{})
+			object := p["object"].(string)
+			if p["schema"].(string) != "" && p["object_type"].(string) != "schema" {
+				object = p["schema"].(string) + "." + object
+			}
+			databaseChanges = append(databaseChanges, fmt.Sprintf("revoke %s on %s %s", p["privilege"], p["object_type"], object))
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		changes[database] = databaseChanges
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, database := range databases {
+		for _, change := range changes[database] {
+			lines = append(lines, fmt.Sprintf("  - %s: %s", database, change))
+		}
+	}
+	if dropRole {
+		lines = append(lines, fmt.Sprintf("  - drop role %s", roleName))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  (nothing to reassign or drop)")
+	}
+
+	return fmt.Errorf(
+		"deletion_dry_run is enabled, role %s was not removed. Removing it would:\n%s",
+		roleName, strings.Join(lines, "\n"),
+	)
+}
+
+// readRoleOwnedObjects describes the objects owned by the role in the current database, e.g. "table public.t1".
+func readRoleOwnedObjects(db QueryAble, roleName string) ([]string, error) {
+	rows, err := db.Query(roleOwnedObjectsQuery, roleName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []string
+	for rows.Next() {
+		var objectType, objectName string
+		if err := rows.Scan(&objectType, &objectName); err != nil {
+			return nil, err
+		}
+		objects = append(objects, fmt.Sprintf("%s %s", objectType, objectName))
+	}
+	return objects, rows.Err()
 }
 
 func resourcePostgreSQLRoleRead(db *DBConnection, d *schema.ResourceData) error {
@@ -345,7 +726,7 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		// select columns
 		strings.Join(columns, ", "),
 	)
-	err := db.QueryRow(roleSQL, roleID).Scan(values...)
+	err := queryRowScan(db, roleSQL, []interface{}{roleID}, values...)
 
 	switch {
 	case err == sql.ErrNoRows:
@@ -361,7 +742,7 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 	var roleConfig pq.ByteaArray
 	settingSQL := `SELECT setconfig FROM pg_catalog.pg_db_role_setting
 		WHERE setrole = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname=$1) AND setdatabase = 0`
-	if settingErr := db.QueryRow(settingSQL, roleID).Scan(&roleConfig); settingErr != nil && settingErr != sql.ErrNoRows {
+	if settingErr := queryRowScan(db, settingSQL, []interface{}{roleID}, &roleConfig); settingErr != nil && settingErr != sql.ErrNoRows {
 		return fmt.Errorf("Error reading role settings from pg_db_role_setting: %w", settingErr)
 	}
 	// If settingErr == sql.ErrNoRows, roleConfig remains nil — no settings configured.
@@ -370,15 +751,7 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 	d.Set(roleCreateDBAttr, roleCreateDB)
 	d.Set(roleCreateRoleAttr, roleCreateRole)
 	d.Set(roleLoginAttr, roleCanLogin)
-	d.Set(roleSkipDropRoleAttr, d.Get(roleSkipDropRoleAttr).(bool))
-	d.Set(roleSkipReassignOwnedAttr, d.Get(roleSkipReassignOwnedAttr).(bool))
-	// CockroachDB stores VALID UNTIL 'infinity' as '294276-12-31 23:59:59' due to a bug.
-	// Normalize it back to "infinity" so the state matches the config default.
-	// https://github.com/cockroachdb/cockroach/issues/116714
-	if strings.HasPrefix(roleValidUntil, "294276-12-31 23:59:59") {
-		roleValidUntil = "infinity"
-	}
-	d.Set(roleValidUntilAttr, roleValidUntil)
+	d.Set(roleValidUntilAttr, normalizeRoleValidUntil(roleValidUntil))
 	d.Set(roleBypassRLSAttr, roleBypassRLS)
 	d.Set(roleRolesAttr, pgArrayToSet(roleRoles))
 	d.Set(roleSearchPathAttr, readSearchPath(roleConfig))
@@ -401,6 +774,28 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 
 	d.Set(defaultTransactionIsolationAttr, readDefaultTransactionIsolation(roleConfig))
 	d.Set(defaultTransactionFollowerReadsAttr, readFollowerReads(roleConfig))
+	d.Set(roleSessionDefaultsAttr, readSessionDefaults(roleConfig, roleManagedSettings))
+
+	databaseSessionDefaults, err := readDatabaseSessionDefaults(db, roleName)
+	if err != nil {
+		return err
+	}
+	d.Set(roleDatabaseSessionDefaultsAttr, databaseSessionDefaults)
+
+	roleOptions, err := readRoleOptions(db, roleName)
+	if err != nil {
+		return err
+	}
+
+	for _, opt := range cockroachdbRoleBoolOptions {
+		if db.featureSupported(opt.feature) {
+			_, enabled := roleOptions[opt.sqlKeyEnable]
+			d.Set(opt.hclKey, enabled)
+		}
+	}
+	if db.featureSupported(featureRoleSubject) {
+		d.Set(roleSubjectAttr, roleOptions["SUBJECT"])
+	}
 
 	password, err := readRolePassword(db, d, roleCanLogin)
 	if err != nil {
@@ -409,9 +804,115 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 
 	d.Set(rolePasswordAttr, password)
 
+	passwordHash, err := readRolePasswordHash(db, d)
+	if err != nil {
+		return err
+	}
+
+	d.Set(rolePasswordHashAttr, passwordHash)
+
 	return nil
 }
 
+// normalizeRoleValidUntil works around CockroachDB storing VALID UNTIL 'infinity' as
+// '294276-12-31 23:59:59', so the state matches the config default.
+// https://github.com/cockroachdb/cockroach/issues/116714
+func normalizeRoleValidUntil(validUntil string) string {
+	if strings.HasPrefix(validUntil, "294276-12-31 23:59:59") {
+		return "infinity"
+	}
+	return validUntil
+}
+
+// readRoleOptions returns the role options listed by SHOW ROLES, keyed by option name.
+// Options without a value (e.g. CONTROLJOB) are mapped to an empty string.
+func readRoleOptions(db QueryAble, roleName string) (map[string]string, error) {
+	var options string
+	err := queryRowScan(db,
+		"WITH a AS (SHOW ROLES) SELECT COALESCE(options::STRING, '') FROM a WHERE username = $1",
+		[]interface{}{roleName}, &options,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return map[string]string{}, nil
+	case err != nil:
+		return nil, fmt.Errorf("could not read options of role %s: %w", roleName, err)
+	}
+
+	return parseRoleOptions(options)
+}
+
+// parseRoleOptions parses the options column of SHOW ROLES, which is either an array
+// (e.g. {CONTROLJOB,"SUBJECT=CN=foo,O=bar"}) or, on older versions, a comma-separated string.
+func parseRoleOptions(options string) (map[string]string, error) {
+	var entries []string
+	if strings.HasPrefix(options, "{") {
+		var array pq.StringArray
+		if err := array.Scan([]byte(options)); err != nil {
+			return nil, fmt.Errorf("could not parse role options %q: %w", options, err)
+		}
+		entries = array
+	} else if options != "" {
+		entries = strings.Split(options, ", ")
+	}
+
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, _ := strings.Cut(entry, "=")
+		result[strings.ToUpper(strings.TrimSpace(name))] = value
+	}
+
+	return result, nil
+}
+
+// readSessionDefaults returns the session variables of a pg_db_role_setting setconfig array,
+// skipping the excluded ones.
+func readSessionDefaults(roleConfig pq.ByteaArray, excluded []string) map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, v := range roleConfig {
+		name, value, found := strings.Cut(string(v), "=")
+		if !found || sliceContainsStr(excluded, name) {
+			continue
+		}
+		settings[name] = value
+	}
+	return settings
+}
+
+// readDatabaseSessionDefaults returns the session variables set for the role in specific databases.
+func readDatabaseSessionDefaults(db QueryAble, roleName string) ([]interface{}, error) {
+	rows, err := db.Query(
+		`SELECT d.datname, s.setconfig FROM pg_catalog.pg_db_role_setting s
+		JOIN pg_catalog.pg_database d ON d.oid = s.setdatabase
+		WHERE s.setrole = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname=$1)
+		ORDER BY d.datname`,
+		roleName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading role database settings from pg_db_role_setting: %w", err)
+	}
+	defer rows.Close()
+
+	databaseSessionDefaults := make([]interface{}, 0)
+	for rows.Next() {
+		var database string
+		var setConfig pq.ByteaArray
+		if err := rows.Scan(&database, &setConfig); err != nil {
+			return nil, fmt.Errorf("could not scan role database settings: %w", err)
+		}
+		settings := readSessionDefaults(setConfig, nil)
+		if len(settings) == 0 {
+			continue
+		}
+		databaseSessionDefaults = append(databaseSessionDefaults, map[string]interface{}{
+			"database": database,
+			"settings": settings,
+		})
+	}
+
+	return databaseSessionDefaults, rows.Err()
+}
+
 // readSearchPath searches for a search_path entry in the rolconfig array.
 // In case no such value is present, it returns nil.
 func readSearchPath(roleConfig pq.ByteaArray) []string {
@@ -490,80 +991,228 @@ func readFollowerReads(roleConfig pq.ByteaArray) string {
 }
 
 // readRolePassword reads password from Terraform state.
+// If the verifier stored in system.users can be read and doesn't match it, the password
+// was changed outside of Terraform and an empty password is returned to force an update.
 func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin bool) (string, error) {
-	return d.Get(rolePasswordAttr).(string), nil
-}
+	password := d.Get(rolePasswordAttr).(string)
+	if password == "" || strings.ToUpper(password) == "NULL" {
+		return password, nil
+	}
 
-func resourcePostgreSQLRoleUpdate(db *DBConnection, d *schema.ResourceData) error {
-	if err := setRolePassword(db, d); err != nil {
-		return err
+	verifier, ok, err := readRolePasswordVerifier(db, d.Id())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return password, nil
 	}
 
-	if db.featureSupported(featureRLS) {
-		if err := setRoleBypassRLS(db, d); err != nil {
-			return err
-		}
+	// Only SCRAM-SHA-256 verifiers can be checked, bcrypt hashes are left alone.
+	scramVerifier, err := ParseScramVerifier(verifier)
+	if err != nil {
+		return password, nil
 	}
 
-	if err := setRoleCreateDB(db, d); err != nil {
-		return err
+	matches, err := scramVerifier.matchesCached(d.Id(), password)
+	if err != nil {
+		return "", fmt.Errorf("could not verify password of role %s: %w", d.Id(), err)
+	}
+	if !matches {
+		log.Printf("[WARN] password of role %s was changed outside of Terraform", d.Id())
+		return "", nil
 	}
 
-	if err := setRoleCreateRole(db, d); err != nil {
-		return err
+	return password, nil
+}
+
+// readRolePasswordHash returns the verifier stored in system.users when password_hash is
+// configured and the stored verifier no longer matches it, so a hash changed outside of
+// Terraform shows up as a diff. Verifiers are compared by salt, iteration count and keys;
+// a verifier with another iteration count is one CockroachDB computed again from the same
+// password on login after the cost setting changed, and isn't reported.
+func readRolePasswordHash(db *DBConnection, d *schema.ResourceData) (string, error) {
+	passwordHash := d.Get(rolePasswordHashAttr).(string)
+	if passwordHash == "" {
+		return "", nil
 	}
 
-	if err := setRoleLogin(db, d); err != nil {
-		return err
+	verifier, ok, err := readRolePasswordVerifier(db, d.Id())
+	if err != nil {
+		return "", err
+	}
+	if !ok || verifier == passwordHash {
+		return passwordHash, nil
 	}
 
-	if err := setRoleValidUntil(db, d); err != nil {
-		return err
+	configured, err := ParseScramVerifier(passwordHash)
+	if err != nil {
+		return verifier, nil
+	}
+	stored, err := ParseScramVerifier(verifier)
+	if err != nil {
+		log.Printf("[WARN] password of role %s is no longer stored as a SCRAM-SHA-256 verifier", d.Id())
+		return verifier, nil
 	}
 
-	// applying roles: let's revoke all / grant the right ones
-	if err := revokeRoles(db, d); err != nil {
-		return err
+	switch {
+	case stored.Equal(configured):
+		return passwordHash, nil
+	case stored.Iterations != configured.Iterations:
+		log.Printf("[DEBUG] password verifier of role %s was rehashed with %d iterations", d.Id(), stored.Iterations)
+		return passwordHash, nil
 	}
 
-	if err := grantRoles(db, d); err != nil {
+	log.Printf("[WARN] password hash of role %s was changed outside of Terraform", d.Id())
+	return verifier, nil
+}
+
+// readRolePasswordVerifier returns the password verifier of the role stored in system.users.
+// It returns false if the connected user isn't allowed to read system.users.
+func readRolePasswordVerifier(db QueryAble, roleName string) (string, bool, error) {
+	var verifier []byte
+	err := queryRowScan(db, `SELECT "hashedPassword" FROM system.users WHERE username = $1`, []interface{}{roleName}, &verifier)
+	switch {
+	case isPqErrorCode(err, pqErrorCodeInsufficientPrivilege):
+		log.Printf("[DEBUG] not allowed to read password verifier of role %s, skipping password drift detection: %v", roleName, err)
+		return "", false, nil
+	case err != nil:
+		return "", false, fmt.Errorf("could not read password verifier of role %s: %w", roleName, err)
+	}
+
+	return string(verifier), true, nil
+}
+
+// readWriteOnlyPassword returns password_wo from the configuration, as it is never stored in the state.
+func readWriteOnlyPassword(d *schema.ResourceData) string {
+	password, diags := d.GetRawConfigAt(cty.GetAttrPath(rolePasswordWOAttr))
+	if diags.HasError() || password.IsNull() || !password.IsKnown() {
+		return ""
+	}
+
+	return password.AsString()
+}
+
+func resourcePostgreSQLRoleUpdate(db *DBConnection, d *schema.ResourceData) error {
+	err := withTransaction(db, func(db *DBConnection, txn QueryAble) error {
+		if err := setRolePassword(txn, d); err != nil {
+			return err
+		}
+
+		if db.featureSupported(featureRLS) {
+			if err := setRoleBypassRLS(db, txn, d); err != nil {
+				return err
+			}
+		} else if d.HasChange(roleBypassRLSAttr) && d.Get(roleBypassRLSAttr).(bool) {
+			warnRoleBypassRLSUnsupported(db)
+		}
+
+		if err := setRoleCreateDB(txn, d); err != nil {
+			return err
+		}
+
+		if err := setRoleCreateRole(txn, d); err != nil {
+			return err
+		}
+
+		if err := setRoleLogin(txn, d); err != nil {
+			return err
+		}
+
+		if err := setRoleValidUntil(txn, d); err != nil {
+			return err
+		}
+
+		for _, opt := range cockroachdbRoleBoolOptions {
+			if err := setRoleBoolOption(db, txn, d, opt); err != nil {
+				return err
+			}
+		}
+
+		if err := setRoleSubject(db, txn, d); err != nil {
+			return err
+		}
+
+		// applying roles: let's revoke all / grant the right ones
+		if err := revokeRoles(txn, d); err != nil {
+			return err
+		}
+
+		if err := grantRoles(txn, d); err != nil {
+			return err
+		}
+
+		return setRoleSettings(db, txn, d)
+	})
+	if err != nil {
 		return err
 	}
 
-	if err := alterSearchPath(db, d); err != nil {
+	return resourcePostgreSQLRoleReadImpl(db, d)
+}
+
+// setRoleSettings applies the session variables of the role which have changed.
+func setRoleSettings(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
+	if err := alterSearchPath(txn, d); err != nil {
 		return err
 	}
 
-	if err := setStatementTimeout(db, d); err != nil {
+	if err := setStatementTimeout(txn, d); err != nil {
 		return err
 	}
 
-	if err := setIdleInTransactionSessionTimeout(db, d); err != nil {
+	if err := setIdleInTransactionSessionTimeout(txn, d); err != nil {
 		return err
 	}
 
 	if db.featureSupported(featureTransactionIsolation) {
-		if err := setDefaultTransactionIsolation(db, d); err != nil {
+		if err := setDefaultTransactionIsolation(txn, d); err != nil {
 			return err
 		}
+	} else if d.HasChange(defaultTransactionIsolationAttr) && d.Get(defaultTransactionIsolationAttr).(string) != "" {
+		db.warn(
+			defaultTransactionIsolationAttr,
+			"default_transaction_isolation is not supported",
+			fmt.Sprintf("CockroachDB %s does not support setting default_transaction_isolation for a role, the setting was skipped.", db.version),
+		)
 	}
 
 	if db.featureSupported(featureFollowerReads) {
-		if err := setDefaultFollowerReads(db, d); err != nil {
+		if err := setDefaultFollowerReads(txn, d); err != nil {
 			return err
 		}
+	} else if d.HasChange(defaultTransactionFollowerReadsAttr) && d.Get(defaultTransactionFollowerReadsAttr).(string) != "" {
+		db.warn(
+			defaultTransactionFollowerReadsAttr,
+			"default_transaction_use_follower_reads is not supported",
+			fmt.Sprintf("CockroachDB %s does not support setting default_transaction_use_follower_reads for a role, the setting was skipped.", db.version),
+		)
 	}
 
-	return resourcePostgreSQLRoleReadImpl(db, d)
+	return setSessionDefaults(txn, d)
 }
 
 func setRolePassword(db QueryAble, d *schema.ResourceData) error {
-	if !d.HasChange(rolePasswordAttr) {
-		return nil
+	var password string
+
+	switch writeOnlyPassword := readWriteOnlyPassword(d); {
+	case d.Get(rolePasswordHashAttr).(string) != "":
+		if !d.HasChange(rolePasswordHashAttr) {
+			return nil
+		}
+		password = d.Get(rolePasswordHashAttr).(string)
+	case writeOnlyPassword != "":
+		if !d.HasChange(rolePasswordVersionAttr) {
+			return nil
+		}
+		password = writeOnlyPassword
+	default:
+		if !d.HasChange(rolePasswordAttr) {
+			return nil
+		}
+		password = d.Get(rolePasswordAttr).(string)
 	}
 
 	roleName := d.Get(roleNameAttr).(string)
-	password := d.Get(rolePasswordAttr).(string)
 
 	sqlStr := fmt.Sprintf("ALTER ROLE %s PASSWORD '%s'", pq.QuoteIdentifier(roleName), pqQuoteLiteral(password))
 	if _, err := db.Exec(sqlStr); err != nil {
@@ -572,13 +1221,22 @@ func setRolePassword(db QueryAble, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleBypassRLS(db *DBConnection, d *schema.ResourceData) error {
+// warnRoleBypassRLSUnsupported warns that bypass_row_level_security is ignored by the server.
+func warnRoleBypassRLSUnsupported(db *DBConnection) {
+	db.warn(
+		roleBypassRLSAttr,
+		"bypass_row_level_security is not supported",
+		fmt.Sprintf("CockroachDB %s does not support row-level security, BYPASSRLS was not granted to the role.", db.version),
+	)
+}
+
+func setRoleBypassRLS(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
 	if !d.HasChange(roleBypassRLSAttr) {
 		return nil
 	}
 
 	if !db.featureSupported(featureRLS) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", db.version.String())
+		return newAttributeError(roleBypassRLSAttr, fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", db.version.String()))
 	}
 
 	bypassRLS := d.Get(roleBypassRLSAttr).(bool)
@@ -588,7 +1246,7 @@ func setRoleBypassRLS(db *DBConnection, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sqlStr := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := db.Exec(sqlStr); err != nil {
+	if _, err := txn.Exec(sqlStr); err != nil {
 		return fmt.Errorf("Error updating role BYPASSRLS: %w", err)
 	}
 
@@ -673,6 +1331,237 @@ func setRoleValidUntil(db QueryAble, d *schema.ResourceData) error {
 	return nil
 }
 
+func setRoleBoolOption(db *DBConnection, txn QueryAble, d *schema.ResourceData, opt roleBoolOption) error {
+	if !d.HasChange(opt.hclKey) {
+		return nil
+	}
+
+	if !db.featureSupported(opt.feature) {
+		return newAttributeError(opt.hclKey, fmt.Errorf("role option %s is not supported for this version (%s)", opt.sqlKeyEnable, db.version))
+	}
+
+	tok := opt.sqlKeyDisable
+	if d.Get(opt.hclKey).(bool) {
+		tok = opt.sqlKeyEnable
+	}
+	roleName := d.Get(roleNameAttr).(string)
+	sqlStr := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
+	if _, err := txn.Exec(sqlStr); err != nil {
+		return fmt.Errorf("Error updating role %s: %w", opt.sqlKeyEnable, err)
+	}
+
+	return nil
+}
+
+func setRoleSubject(db *DBConnection, txn QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(roleSubjectAttr) {
+		return nil
+	}
+
+	if !db.featureSupported(featureRoleSubject) {
+		return newAttributeError(roleSubjectAttr, fmt.Errorf("role option SUBJECT is not supported for this version (%s)", db.version))
+	}
+
+	subject := "NULL"
+	if v := d.Get(roleSubjectAttr).(string); v != "" {
+		subject = fmt.Sprintf("'%s'", pqQuoteLiteral(v))
+	}
+	roleName := d.Get(roleNameAttr).(string)
+	sqlStr := fmt.Sprintf("ALTER ROLE %s WITH SUBJECT %s", pq.QuoteIdentifier(roleName), subject)
+	if _, err := txn.Exec(sqlStr); err != nil {
+		return fmt.Errorf("Error updating role SUBJECT: %w", err)
+	}
+
+	return nil
+}
+
+func validateSessionVariableNames(v interface{}, key string) (warnings []string, errors []error) {
+	for name := range v.(map[string]interface{}) {
+		if !sessionVariableRegexp.MatchString(name) {
+			errors = append(errors, fmt.Errorf("%s: invalid session variable name %q", key, name))
+		}
+	}
+	return
+}
+
+func validateRoleSessionDefaults(v interface{}, key string) (warnings []string, errors []error) {
+	warnings, errors = validateSessionVariableNames(v, key)
+	for name := range v.(map[string]interface{}) {
+		if sliceContainsStr(roleManagedSettings, name) {
+			errors = append(errors, fmt.Errorf("%s: %q must be set with its dedicated attribute", key, name))
+		}
+	}
+	return
+}
+
+// sessionDurationVariables are the session variables holding a duration, which CockroachDB
+// stores in milliseconds.
+var sessionDurationVariables = []string{
+	"statement_timeout",
+	"lock_timeout",
+	"idle_in_transaction_session_timeout",
+	"idle_in_session_timeout",
+	"idle_session_timeout",
+	"transaction_timeout",
+}
+
+// sessionEnumVariables are the session variables holding a boolean or a keyword, which
+// CockroachDB stores in lower case.
+var sessionEnumVariables = []string{
+	"default_transaction_isolation",
+	"default_transaction_priority",
+	"default_transaction_quality_of_service",
+	"default_transaction_read_only",
+	"default_transaction_use_follower_reads",
+	"bytea_output",
+	"client_min_messages",
+	"datestyle",
+	"disallow_full_table_scans",
+	"distsql",
+	"enable_implicit_select_for_update",
+	"enable_zigzag_join",
+	"intervalstyle",
+	"optimizer_use_histograms",
+	"require_explicit_primary_keys",
+	"serial_normalization",
+	"sql_safe_updates",
+	"vectorize",
+}
+
+// normalizeSessionDefault returns the canonical form of the value of the session variable
+// name, so that values normalized by CockroachDB (e.g. `ON` stored as `on`, `30s` as
+// `30000`) compare equal to the configured ones. Only the values of known duration and enum
+// variables are normalized, the others are compared as is.
+func normalizeSessionDefault(name, value string) string {
+	name = strings.ToLower(name)
+
+	switch {
+	case sliceContainsStr(sessionDurationVariables, name):
+		value = strings.ToLower(strings.Trim(strings.TrimSpace(value), "'"))
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return (time.Duration(ms) * time.Millisecond).String()
+		}
+		if duration, err := time.ParseDuration(strings.ReplaceAll(value, "min", "m")); err == nil {
+			return duration.String()
+		}
+		return value
+	case sliceContainsStr(sessionEnumVariables, name):
+		value = strings.ToLower(strings.Trim(strings.TrimSpace(value), "'"))
+		switch value {
+		case "on", "true", "yes":
+			return "on"
+		case "off", "false", "no":
+			return "off"
+		}
+		return value
+	}
+	return value
+}
+
+// sessionDefaultDiffSuppressFunc suppresses the diff of session variables whose values only
+// differ by their normalization. k is the path of the map element, e.g.
+// `session_defaults.statement_timeout`.
+func sessionDefaultDiffSuppressFunc(k, old, new string, d *schema.ResourceData) bool {
+	name := k[strings.LastIndex(k, ".")+1:]
+	return normalizeSessionDefault(name, old) == normalizeSessionDefault(name, new)
+}
+
+// databaseSessionDefaultsHash hashes a database_session_defaults block on its database and
+// normalized settings.
+func databaseSessionDefaultsHash(v interface{}) int {
+	block := v.(map[string]interface{})
+	settings := block["settings"].(map[string]interface{})
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	buf.WriteString(block["database"].(string))
+	for _, name := range names {
+		fmt.Fprintf(&buf, ";%s=%s", name, normalizeSessionDefault(name, settings[name].(string)))
+	}
+	return schema.HashString(buf.String())
+}
+
+func setSessionDefaults(db QueryAble, d *schema.ResourceData) error {
+	roleName := pq.QuoteIdentifier(d.Get(roleNameAttr).(string))
+
+	if d.HasChange(roleSessionDefaultsAttr) {
+		oldSettings, newSettings := d.GetChange(roleSessionDefaultsAttr)
+		if err := alterRoleSettings(db, roleName, "", oldSettings.(map[string]interface{}), newSettings.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if !d.HasChange(roleDatabaseSessionDefaultsAttr) {
+		return nil
+	}
+
+	oldRaw, newRaw := d.GetChange(roleDatabaseSessionDefaultsAttr)
+	oldDatabases := databaseSessionDefaultsToMap(oldRaw.(*schema.Set))
+	newDatabases := databaseSessionDefaultsToMap(newRaw.(*schema.Set))
+
+	for database, oldSettings := range oldDatabases {
+		if _, ok := newDatabases[database]; !ok {
+			if err := alterRoleSettings(db, roleName, database, oldSettings, nil); err != nil {
+				return err
+			}
+		}
+	}
+	for database, newSettings := range newDatabases {
+		if err := alterRoleSettings(db, roleName, database, oldDatabases[database], newSettings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// databaseSessionDefaultsToMap returns the settings of each database_session_defaults block keyed by database.
+func databaseSessionDefaultsToMap(blocks *schema.Set) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{}, blocks.Len())
+	for _, block := range blocks.List() {
+		b := block.(map[string]interface{})
+		result[b["database"].(string)] = b["settings"].(map[string]interface{})
+	}
+	return result
+}
+
+// alterRoleSettings resets the session variables removed between oldSettings and newSettings and
+// sets the added or changed ones. role is the quoted role name or ALL, database is empty for
+// settings that apply to every database.
+func alterRoleSettings(db QueryAble, role, database string, oldSettings, newSettings map[string]interface{}) error {
+	target := role
+	if database != "" {
+		target = fmt.Sprintf("%s IN DATABASE %s", role, pq.QuoteIdentifier(database))
+	}
+
+	for name := range oldSettings {
+		if _, ok := newSettings[name]; ok {
+			continue
+		}
+		sqlStr := fmt.Sprintf("ALTER ROLE %s RESET %s", target, name)
+		if _, err := db.Exec(sqlStr); err != nil {
+			return fmt.Errorf("could not reset %s for %s: %w", name, target, err)
+		}
+	}
+
+	for name, value := range newSettings {
+		if oldValue, ok := oldSettings[name]; ok && oldValue == value {
+			continue
+		}
+		sqlStr := fmt.Sprintf("ALTER ROLE %s SET %s = %s", target, name, pq.QuoteLiteral(value.(string)))
+		if _, err := db.Exec(sqlStr); err != nil {
+			return fmt.Errorf("could not set %s for %s: %w", name, target, err)
+		}
+	}
+
+	return nil
+}
+
 func revokeRoles(db QueryAble, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
@@ -0,0 +1,247 @@
+package postgresql
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	roleMembersRoleAttr            = "role"
+	roleMembersMemberAttr          = "member"
+	roleMembersNameAttr            = "name"
+	roleMembersWithAdminOptionAttr = "with_admin_option"
+
+	// CockroachDB doesn't allow to revoke root from the admin role.
+	adminRole = "admin"
+	rootRole  = "root"
+)
+
+func resourcePostgreSQLRoleMembers() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLRoleMembersCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLRoleMembersRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLRoleMembersUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLRoleMembersDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			roleMembersRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the group role whose members are managed",
+			},
+			roleMembersMemberAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleMembersNameAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the member role",
+						},
+						roleMembersWithAdminOptionAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Permit the member to grant the role to others",
+						},
+					},
+				},
+				Description: "The complete list of members of the role. Members not listed are revoked",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLRoleMembersCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return fmt.Errorf(
+			"postgresql_role_members resource is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	role := d.Get(roleMembersRoleAttr).(string)
+	members := roleMembersToMap(d.Get(roleMembersMemberAttr).(*schema.Set))
+	if err := withTransaction(db, func(_ *DBConnection, txn QueryAble) error { return setRoleMembers(txn, role, members) }); err != nil {
+		return err
+	}
+
+	d.SetId(role)
+
+	return readRoleMembers(db, d)
+}
+
+func resourcePostgreSQLRoleMembersRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return fmt.Errorf(
+			"postgresql_role_members resource is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	return readRoleMembers(db, d)
+}
+
+func resourcePostgreSQLRoleMembersUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return fmt.Errorf(
+			"postgresql_role_members resource is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	if d.HasChange(roleMembersMemberAttr) {
+		members := roleMembersToMap(d.Get(roleMembersMemberAttr).(*schema.Set))
+		if err := withTransaction(db, func(_ *DBConnection, txn QueryAble) error { return setRoleMembers(txn, d.Id(), members) }); err != nil {
+			return err
+		}
+	}
+
+	return readRoleMembers(db, d)
+}
+
+func resourcePostgreSQLRoleMembersDelete(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return fmt.Errorf(
+			"postgresql_role_members resource is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	if err := withTransaction(db, func(_ *DBConnection, txn QueryAble) error { return setRoleMembers(txn, d.Id(), map[string]bool{}) }); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func readRoleMembers(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Id()
+
+	exists, err := roleExists(db, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("[WARN] PostgreSQL role (%s) not found, removing its members from state", role)
+		d.SetId("")
+		return nil
+	}
+
+	members, err := getRoleMembers(db, role)
+	if err != nil {
+		return err
+	}
+
+	configured := roleMembersToMap(d.Get(roleMembersMemberAttr).(*schema.Set))
+
+	memberList := make([]interface{}, 0, len(members))
+	for name, withAdminOption := range members {
+		// root is only reported as a member of admin when configured, as it can't be revoked.
+		if _, ok := configured[name]; !ok && isReservedRoleMember(role, name) {
+			continue
+		}
+		memberList = append(memberList, map[string]interface{}{
+			roleMembersNameAttr:            name,
+			roleMembersWithAdminOptionAttr: withAdminOption,
+		})
+	}
+
+	d.Set(roleMembersRoleAttr, role)
+	d.Set(roleMembersMemberAttr, memberList)
+
+	return nil
+}
+
+// getRoleMembers returns the direct members of *role*, mapped to whether they hold the admin option.
+func getRoleMembers(db QueryAble, role string) (map[string]bool, error) {
+	rows, err := db.Query(
+		"SELECT pg_get_userbyid(member), admin_option FROM pg_catalog.pg_auth_members WHERE pg_get_userbyid(roleid) = $1",
+		role,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not read members of role %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	members := make(map[string]bool)
+	for rows.Next() {
+		var member string
+		var withAdminOption bool
+		if err := rows.Scan(&member, &withAdminOption); err != nil {
+			return nil, fmt.Errorf("could not scan member of role %s: %w", role, err)
+		}
+		members[member] = withAdminOption
+	}
+
+	return members, rows.Err()
+}
+
+// setRoleMembers makes *members* the complete list of members of *role*: missing members are granted,
+// unknown members are revoked and the admin option is granted or revoked where it differs.
+func setRoleMembers(db QueryAble, role string, members map[string]bool) error {
+	currentMembers, err := getRoleMembers(db, role)
+	if err != nil {
+		return err
+	}
+
+	for member := range currentMembers {
+		if _, ok := members[member]; ok || isReservedRoleMember(role, member) {
+			continue
+		}
+		if _, err := revokeRoleMembership(db, role, member); err != nil {
+			return err
+		}
+	}
+
+	for member, withAdminOption := range members {
+		if _, err := grantRoleMembership(db, role, member); err != nil {
+			return err
+		}
+		if currentMembers[member] != withAdminOption {
+			if err := setRoleAdminOption(db, role, member, withAdminOption); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isReservedRoleMember returns true if the membership of *member* in *role* can't be revoked.
+func isReservedRoleMember(role, member string) bool {
+	return role == adminRole && member == rootRole
+}
+
+// setRoleAdminOption grants or revokes the admin option of *member* on *role*.
+func setRoleAdminOption(db QueryAble, role, member string, withAdminOption bool) error {
+	query := fmt.Sprintf("REVOKE ADMIN OPTION FOR %s FROM %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+	if withAdminOption {
+		query = fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+	}
+
+	log.Printf("[DEBUG] setting admin option of %s on %s to %t", member, role, withAdminOption)
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("could not set admin option of %s on role %s: %w", member, role, err)
+	}
+	return nil
+}
+
+func roleMembersToMap(members *schema.Set) map[string]bool {
+	result := make(map[string]bool, members.Len())
+	for _, m := range members.List() {
+		member := m.(map[string]interface{})
+		result[member[roleMembersNameAttr].(string)] = member[roleMembersWithAdminOptionAttr].(bool)
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestRoleMembersToMap(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRoleMembers().Schema, map[string]interface{}{
+		"role": "group",
+		"member": []interface{}{
+			map[string]interface{}{"name": "alice", "with_admin_option": true},
+			map[string]interface{}{"name": "bob"},
+		},
+	})
+
+	expected := map[string]bool{"alice": true, "bob": false}
+	if out := roleMembersToMap(d.Get("member").(*schema.Set)); !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, expected)
+	}
+}
+
+func TestIsReservedRoleMember(t *testing.T) {
+	if !isReservedRoleMember("admin", "root") {
+		t.Fatalf("expected root to be a reserved member of admin")
+	}
+	if isReservedRoleMember("admin", "alice") || isReservedRoleMember("group", "root") {
+		t.Fatalf("expected only root to be a reserved member of admin")
+	}
+}
+
+func TestAccPostgresqlRoleMembers(t *testing.T) {
+	skipIfNotAcc(t)
+
+	members := []string{"test_role_members_1", "test_role_members_2", "test_role_members_3"}
+	for _, member := range members {
+		defer createTestRole(t, member)()
+	}
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	var configCreate = `
+resource "postgresql_role" "group" {
+  name = "test_role_members_group"
+}
+
+resource "postgresql_role_members" "group" {
+  role = postgresql_role.group.name
+
+  member {
+    name              = "test_role_members_1"
+    with_admin_option = true
+  }
+
+  member {
+    name = "test_role_members_2"
+  }
+}
+`
+
+	var configUpdate = `
+resource "postgresql_role" "group" {
+  name = "test_role_members_group"
+}
+
+resource "postgresql_role_members" "group" {
+  role = postgresql_role.group.name
+
+  member {
+    name = "test_role_members_1"
+  }
+
+  member {
+    name = "test_role_members_3"
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckRoleMembersDestroy(dsn, "test_role_members_group"),
+		Steps: []resource.TestStep{
+			{
+				Config: configCreate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role_members.group", "id", "test_role_members_group"),
+					resource.TestCheckResourceAttr("postgresql_role_members.group", "member.#", "2"),
+					checkGrantRole(t, dsn, "test_role_members_1", "test_role_members_group", true),
+					checkGrantRole(t, dsn, "test_role_members_2", "test_role_members_group", false),
+				),
+			},
+			{
+				// test_role_members_3 is granted outside of Terraform and kept, test_role_members_2 is revoked.
+				PreConfig: func() {
+					dbExecute(t, dsn, "GRANT test_role_members_group TO test_role_members_3")
+				},
+				Config: configUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role_members.group", "member.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_role_members.group", "member.*", map[string]string{
+						"name":              "test_role_members_1",
+						"with_admin_option": "false",
+					}),
+					checkGrantRole(t, dsn, "test_role_members_1", "test_role_members_group", false),
+					checkGrantRole(t, dsn, "test_role_members_3", "test_role_members_group", false),
+					testAccCheckRoleNotMember(dsn, "test_role_members_2", "test_role_members_group"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_role_members.group",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckRoleNotMember(dsn, member, role string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		isMember, err := isMemberOfRole(db, role, member)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			return fmt.Errorf("role %s is still a member of %s", member, role)
+		}
+		return nil
+	}
+}
+
+func testAccCheckRoleMembersDestroy(dsn, role string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		members, err := getRoleMembers(db, role)
+		if err != nil {
+			return err
+		}
+		if len(members) > 0 {
+			return fmt.Errorf("role %s still has members: %v", role, members)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	roleSessionDefaultsDatabaseAttr = "database"
+	roleSessionDefaultsSettingsAttr = "settings"
+
+	// roleSessionDefaultsAllDatabasesID is the ID of the defaults which apply to every database.
+	roleSessionDefaultsAllDatabasesID = "*"
+)
+
+func resourcePostgreSQLRoleSessionDefaults() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLRoleSessionDefaultsCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLRoleSessionDefaultsRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLRoleSessionDefaultsUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLRoleSessionDefaultsDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			roleSessionDefaultsDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database the session defaults apply to. Applies to every database when omitted",
+			},
+			roleSessionDefaultsSettingsAttr: {
+				Type:         schema.TypeMap,
+				Required:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateSessionVariableNames,
+				Description:  "Default values of session variables for all roles, applied with ALTER ROLE ALL ... SET",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLRoleSessionDefaultsCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := d.Get(roleSessionDefaultsDatabaseAttr).(string)
+	settings := d.Get(roleSessionDefaultsSettingsAttr).(map[string]interface{})
+
+	err := withTransaction(db, func(_ *DBConnection, txn QueryAble) error {
+		return alterRoleSettings(txn, "ALL", database, nil, settings)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(generateRoleSessionDefaultsID(database))
+
+	return resourcePostgreSQLRoleSessionDefaultsReadImpl(db, d)
+}
+
+func resourcePostgreSQLRoleSessionDefaultsRead(db *DBConnection, d *schema.ResourceData) error {
+	return resourcePostgreSQLRoleSessionDefaultsReadImpl(db, d)
+}
+
+func resourcePostgreSQLRoleSessionDefaultsReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	database := ""
+	if d.Id() != roleSessionDefaultsAllDatabasesID {
+		database = d.Id()
+	}
+
+	var setConfig pq.ByteaArray
+	var err error
+	if database == "" {
+		err = queryRowScan(db,
+			"SELECT setconfig FROM pg_catalog.pg_db_role_setting WHERE setrole = 0 AND setdatabase = 0",
+			nil, &setConfig,
+		)
+	} else {
+		exists, existsErr := dbExists(db, database)
+		if existsErr != nil {
+			return existsErr
+		}
+		if !exists {
+			log.Printf("[WARN] PostgreSQL database (%s) for role session defaults not found", database)
+			d.SetId("")
+			return nil
+		}
+
+		err = queryRowScan(db,
+			`SELECT setconfig FROM pg_catalog.pg_db_role_setting
+			WHERE setrole = 0 AND setdatabase = (SELECT oid FROM pg_catalog.pg_database WHERE datname = $1)`,
+			[]interface{}{database}, &setConfig,
+		)
+	}
+	// If err == sql.ErrNoRows, setConfig remains nil — no settings configured.
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("Error reading role session defaults from pg_db_role_setting: %w", err)
+	}
+
+	d.Set(roleSessionDefaultsDatabaseAttr, database)
+	d.Set(roleSessionDefaultsSettingsAttr, readSessionDefaults(setConfig, nil))
+
+	return nil
+}
+
+func resourcePostgreSQLRoleSessionDefaultsUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if d.HasChange(roleSessionDefaultsSettingsAttr) {
+		oldSettings, newSettings := d.GetChange(roleSessionDefaultsSettingsAttr)
+		database := d.Get(roleSessionDefaultsDatabaseAttr).(string)
+
+		err := withTransaction(db, func(_ *DBConnection, txn QueryAble) error {
+			return alterRoleSettings(txn, "ALL", database, oldSettings.(map[string]interface{}), newSettings.(map[string]interface{}))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLRoleSessionDefaultsReadImpl(db, d)
+}
+
+func resourcePostgreSQLRoleSessionDefaultsDelete(db *DBConnection, d *schema.ResourceData) error {
+	database := d.Get(roleSessionDefaultsDatabaseAttr).(string)
+	settings := d.Get(roleSessionDefaultsSettingsAttr).(map[string]interface{})
+
+	// Only the settings in state are reset, so defaults of the scope which were never
+	// managed by this resource are left in place.
+	err := withTransaction(db, func(_ *DBConnection, txn QueryAble) error {
+		return alterRoleSettings(txn, "ALL", database, settings, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func generateRoleSessionDefaultsID(database string) string {
+	if database == "" {
+		return roleSessionDefaultsAllDatabasesID
+	}
+	return database
+}
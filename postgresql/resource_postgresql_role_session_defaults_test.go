@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
+)
+
+func TestGenerateRoleSessionDefaultsID(t *testing.T) {
+	if out := generateRoleSessionDefaultsID(""); out != "*" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, "*")
+	}
+	if out := generateRoleSessionDefaultsID("analytics"); out != "analytics" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, "analytics")
+	}
+}
+
+func TestAccPostgresqlRoleSessionDefaults_Database(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var configCreate = fmt.Sprintf(`
+resource "postgresql_role_session_defaults" "analytics" {
+  database = "%s"
+  settings = {
+    application_name = "analytics"
+    timezone         = "UTC"
+  }
+}
+`, dbName)
+
+	var configUpdate = fmt.Sprintf(`
+resource "postgresql_role_session_defaults" "analytics" {
+  database = "%s"
+  settings = {
+    application_name = "analytics_v2"
+  }
+}
+`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckRoleSessionDefaultsDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: configCreate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role_session_defaults.analytics", "id", dbName),
+					resource.TestCheckResourceAttr("postgresql_role_session_defaults.analytics", "settings.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_role_session_defaults.analytics", "settings.timezone", "UTC"),
+				),
+			},
+			{
+				Config: configUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role_session_defaults.analytics", "settings.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_role_session_defaults.analytics", "settings.application_name", "analytics_v2"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_role_session_defaults.analytics",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckRoleSessionDefaultsDestroy(database string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var setConfig pq.ByteaArray
+		err = db.QueryRow(
+			`SELECT setconfig FROM pg_catalog.pg_db_role_setting
+			WHERE setrole = 0 AND setdatabase = (SELECT oid FROM pg_catalog.pg_database WHERE datname = $1)`,
+			database,
+		).Scan(&setConfig)
+		switch {
+		case err == sql.ErrNoRows:
+			return nil
+		case err != nil:
+			return fmt.Errorf("Error reading role session defaults: %s", err)
+		}
+
+		if len(setConfig) > 0 {
+			return fmt.Errorf("role session defaults still exist for database %s: %v", database, setConfig)
+		}
+		return nil
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
 )
 
 func TestAccPostgresqlRole_Basic(t *testing.T) {
@@ -390,6 +393,477 @@ resource "postgresql_role" "create_role_role" {
 	})
 }
 
+func TestParseRoleOptions(t *testing.T) {
+	cases := []struct {
+		options  string
+		expected map[string]string
+	}{
+		{"", map[string]string{}},
+		{"{}", map[string]string{}},
+		{"CONTROLJOB, NOSQLLOGIN", map[string]string{"CONTROLJOB": "", "NOSQLLOGIN": ""}},
+		{
+			`{CONTROLJOB,"SUBJECT=CN=foo,O=bar","VALID UNTIL=2099-01-01 00:00:00+00"}`,
+			map[string]string{"CONTROLJOB": "", "SUBJECT": "CN=foo,O=bar", "VALID UNTIL": "2099-01-01 00:00:00+00"},
+		},
+	}
+
+	for _, c := range cases {
+		out, err := parseRoleOptions(c.options)
+		if err != nil {
+			t.Fatalf("Error parsing role options %q: %v", c.options, err)
+		}
+		if !reflect.DeepEqual(out, c.expected) {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
+		}
+	}
+}
+
+func TestAccPostgresqlRole_CockroachDBOptions(t *testing.T) {
+	var config = `
+resource "postgresql_role" "crdb_options" {
+  name                   = "crdb_options_role"
+  control_job            = %[1]t
+  control_changefeed     = %[1]t
+  view_activity          = %[1]t
+  cancel_query           = %[1]t
+  modify_cluster_setting = %[1]t
+  create_login           = %[1]t
+  no_sql_login           = %[1]t
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRoleNoSQLLogin)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("crdb_options_role", nil, nil),
+					resource.TestCheckResourceAttr("postgresql_role.crdb_options", "control_job", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.crdb_options", "view_activity", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.crdb_options", "no_sql_login", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.crdb_options", "control_job", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.crdb_options", "cancel_query", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.crdb_options", "no_sql_login", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_Subject(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRoleSubject)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_role" "subject" {
+  name    = "subject_role"
+  login   = true
+  subject = "CN=subject_role,O=Cockroach"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.subject", "subject", "CN=subject_role,O=Cockroach"),
+				),
+			},
+			{
+				Config: `
+resource "postgresql_role" "subject" {
+  name  = "subject_role"
+  login = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.subject", "subject", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestReadSessionDefaults(t *testing.T) {
+	roleConfig := pq.ByteaArray{
+		[]byte("search_path=foo"),
+		[]byte("application_name=my_app"),
+		[]byte("timezone=UTC"),
+	}
+
+	out := readSessionDefaults(roleConfig, roleManagedSettings)
+	expected := map[string]interface{}{"application_name": "my_app", "timezone": "UTC"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", out, expected)
+	}
+}
+
+func TestValidateRoleSessionDefaults(t *testing.T) {
+	cases := []struct {
+		settings  map[string]interface{}
+		shouldErr bool
+	}{
+		{map[string]interface{}{"application_name": "app", "sql.defaults.distsql": "off"}, false},
+		{map[string]interface{}{"search_path": "foo"}, true},
+		{map[string]interface{}{"timezone = 'UTC'; DROP ROLE x; --": "x"}, true},
+	}
+
+	for _, c := range cases {
+		_, errs := validateRoleSessionDefaults(c.settings, roleSessionDefaultsAttr)
+		if (len(errs) > 0) != c.shouldErr {
+			t.Fatalf("Error matching output and expected for %#v: %v", c.settings, errs)
+		}
+	}
+}
+
+func TestNormalizeSessionDefault(t *testing.T) {
+	cases := []struct {
+		name       string
+		configured string
+		stored     string
+		equal      bool
+	}{
+		{"enable_zigzag_join", "ON", "on", true},
+		{"sql_safe_updates", "true", "on", true},
+		{"statement_timeout", "30s", "30000", true},
+		{"lock_timeout", "1min", "60000", true},
+		{"statement_timeout", "30s", "31s", false},
+		{"application_name", "MyApp", "myapp", false},
+		{"application_name", "30s", "30000", false},
+		{"timezone", "UTC", "UTC", true},
+	}
+
+	for _, c := range cases {
+		configured := normalizeSessionDefault(c.name, c.configured)
+		stored := normalizeSessionDefault(c.name, c.stored)
+		if (configured == stored) != c.equal {
+			t.Fatalf("Error matching output and expected for %s: %#v vs %#v", c.name, configured, stored)
+		}
+	}
+}
+
+func TestAccPostgresqlRole_SessionDefaults(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var configCreate = fmt.Sprintf(`
+resource "postgresql_role" "session_defaults" {
+  name = "session_defaults_role"
+
+  session_defaults = {
+    application_name = "my_app"
+    timezone         = "UTC"
+  }
+
+  database_session_defaults {
+    database = "%s"
+    settings = {
+      application_name = "my_app_in_db"
+    }
+  }
+}
+`, dbName)
+
+	// CockroachDB normalizes the values it stores, which must not show up as a diff.
+	var configUpdate = fmt.Sprintf(`
+resource "postgresql_role" "session_defaults" {
+  name = "session_defaults_role"
+
+  session_defaults = {
+    application_name = "my_other_app"
+    timezone         = "America/New_York"
+  }
+
+  database_session_defaults {
+    database = "%s"
+    settings = {
+      enable_zigzag_join = "ON"
+    }
+  }
+}
+`, dbName)
+
+	var configRemove = `
+resource "postgresql_role" "session_defaults" {
+  name = "session_defaults_role"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configCreate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "session_defaults.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "session_defaults.application_name", "my_app"),
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "database_session_defaults.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_role.session_defaults", "database_session_defaults.*", map[string]string{
+						"database":                  dbName,
+						"settings.application_name": "my_app_in_db",
+					}),
+				),
+			},
+			{
+				Config: configUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "session_defaults.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "session_defaults.application_name", "my_other_app"),
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "session_defaults.timezone", "America/New_York"),
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "database_session_defaults.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_role.session_defaults", "database_session_defaults.*", map[string]string{
+						"database":                    dbName,
+						"settings.enable_zigzag_join": "on",
+					}),
+				),
+			},
+			{
+				// Removing the attributes from the configuration resets the session variables.
+				Config: configRemove,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "session_defaults.%", "0"),
+					resource.TestCheckResourceAttr("postgresql_role.session_defaults", "database_session_defaults.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_DeletionSettings(t *testing.T) {
+	skipIfNotAcc(t)
+
+	// The new owner is created first so it is dropped after the test database
+	// which holds the reassigned table.
+	newOwner := acctest.RandomWithPrefix("tf_tests_role_deletion_new_owner")
+	defer createTestRole(t, newOwner)()
+	roleName := acctest.RandomWithPrefix("tf_tests_role_deletion_owner")
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var config = `
+resource "postgresql_role" "owner" {
+  name              = "%s"
+  reassign_owned_to = "%s"
+  include_databases = ["%s"]
+  deletion_dry_run  = %t
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckPostgresqlRoleDestroy,
+			func(s *terraform.State) error {
+				testConfig := getTestConfig(t)
+				db, err := sql.Open("postgres", testConfig.connStr(dbName))
+				if err != nil {
+					return err
+				}
+				defer db.Close()
+
+				var owner string
+				if err := db.QueryRow("SELECT tableowner FROM pg_tables WHERE tablename = 'owned_table'").Scan(&owner); err != nil {
+					return fmt.Errorf("could not read owner of owned_table: %w", err)
+				}
+				if owner != newOwner {
+					return fmt.Errorf("expected owned_table to be reassigned to %s, got %s", newOwner, owner)
+				}
+				return nil
+			},
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, roleName, newOwner, dbName, true),
+				Check:  testAccCheckPostgresqlRoleExists(roleName, nil, nil),
+			},
+			{
+				PreConfig: func() {
+					createTestTables(t, dbSuffix, []string{"test_schema.owned_table"}, roleName)
+				},
+				Config:      fmt.Sprintf(config, roleName, newOwner, dbName, true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("reassign table test_schema.owned_table to " + newOwner),
+			},
+			{
+				Config: fmt.Sprintf(config, roleName, newOwner, dbName, false),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_DeletionDryRunSkipReassign(t *testing.T) {
+	var config = `
+resource "postgresql_role" "dry_run" {
+  name                = "role_deletion_dry_run"
+  skip_reassign_owned = true
+  deletion_dry_run    = %t
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, true),
+				Check:  testAccCheckPostgresqlRoleExists("role_deletion_dry_run", nil, nil),
+			},
+			{
+				// Skipping REASSIGN OWNED doesn't bypass the dry run, the role is kept.
+				Config:      fmt.Sprintf(config, true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("drop role role_deletion_dry_run"),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check:  testAccCheckPostgresqlRoleExists("role_deletion_dry_run", nil, nil),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_DeletionProtection(t *testing.T) {
+	var config = `
+resource "postgresql_role" "protected" {
+  name                = "role_deletion_protected"
+  deletion_protection = %t
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("role_deletion_protected", nil, nil),
+					resource.TestCheckResourceAttr("postgresql_role.protected", "deletion_protection", "true"),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(config, true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`deletion_protection is set to true`),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("role_deletion_protected", nil, nil),
+					resource.TestCheckResourceAttr("postgresql_role.protected", "deletion_protection", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_DeletionGuard(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var config = `
+resource "postgresql_role" "guarded" {
+  name              = "role_deletion_guarded"
+  include_databases = ["%s"]
+  deletion_guard    = true
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, dbName),
+				Check:  testAccCheckPostgresqlRoleExists("role_deletion_guarded", nil, nil),
+			},
+			{
+				PreConfig: func() {
+					createTestTables(t, dbSuffix, []string{"test_schema.guarded_table"}, "role_deletion_guarded")
+				},
+				Config:      fmt.Sprintf(config, dbName),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("owns objects"),
+			},
+			{
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dbExecute(t, testConfig.connStr(dbName), "DROP TABLE test_schema.guarded_table")
+				},
+				Config: fmt.Sprintf(config, dbName),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_CreateIsAtomic(t *testing.T) {
+	var config = `
+resource "postgresql_role" "atomic" {
+  name  = "role_create_atomic"
+  roles = [%s]
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Granting a missing role fails and rolls back the role creation.
+				Config:      fmt.Sprintf(config, `"role_create_atomic_missing"`),
+				ExpectError: regexp.MustCompile("could not grant role role_create_atomic_missing"),
+			},
+			{
+				// Creating the role again would fail if it had been left behind.
+				Config: fmt.Sprintf(config, ""),
+				Check:  testAccCheckPostgresqlRoleExists("role_create_atomic", nil, nil),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlRole_BypassRLS(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -539,6 +1013,81 @@ resource "postgresql_role" "skip_drop" {
 	})
 }
 
+func TestAccPostgresqlRole_WriteOnlyPassword(t *testing.T) {
+	var config = `
+resource "postgresql_role" "wo_password" {
+  name             = "wo_password_role"
+  login            = true
+  password_wo      = "%s"
+  password_version = %d
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "first", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.wo_password", "password_wo", ""),
+					testAccCheckRoleCanLogin(t, "wo_password_role", "first"),
+				),
+			},
+			{
+				// Changing the password without bumping the version is not applied.
+				Config:   fmt.Sprintf(config, "second", 1),
+				PlanOnly: true,
+			},
+			{
+				Config: fmt.Sprintf(config, "second", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.wo_password", "password_version", "2"),
+					testAccCheckRoleCanLogin(t, "wo_password_role", "second"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_PasswordHash(t *testing.T) {
+	var config = `
+resource "postgresql_role" "hashed_password" {
+  name          = "hashed_password_role"
+  login         = true
+  password_hash = "%s"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, newTestScramVerifier(t, "hashed")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRoleCanLogin(t, "hashed_password_role", "hashed"),
+				),
+			},
+			{
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dbExecute(t, testConfig.connStr("postgres"), `ALTER ROLE hashed_password_role PASSWORD 'changed'`)
+				},
+				Config:             fmt.Sprintf(config, newTestScramVerifier(t, "hashed")),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlRole_Import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -566,9 +1115,9 @@ resource "postgresql_role" "import_role" {
 				ResourceName:      "postgresql_role.import_role",
 				ImportState:       true,
 				ImportStateVerify: true,
-				// password is not readable from the DB; skip_drop_role and skip_reassign_owned
+				// password is not readable from the DB; the skip_* and deletion_* attributes
 				// are local-only flags not stored in the database.
-				ImportStateVerifyIgnore: []string{"password", "skip_drop_role", "skip_reassign_owned"},
+				ImportStateVerifyIgnore: []string{"password", "skip_drop_role", "skip_reassign_owned", "deletion_parallelism", "deletion_dry_run", "deletion_protection", "deletion_guard"},
 			},
 		},
 	})
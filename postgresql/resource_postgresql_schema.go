@@ -23,11 +23,10 @@ const (
 
 func resourcePostgreSQLSchema() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLSchemaCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLSchemaRead),
-		Update: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLSchemaDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLSchemaExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLSchemaCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSchemaRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSchemaDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -93,7 +92,7 @@ func createSchemaWithDB(db *DBConnection, d *schema.ResourceData) error {
 
 	// Check if previous tasks haven't already created schema
 	var foundSchema bool
-	err := db.QueryRow(`SELECT TRUE FROM pg_catalog.pg_namespace WHERE nspname = $1`, schemaName).Scan(&foundSchema)
+	err := queryRowScan(db, `SELECT TRUE FROM pg_catalog.pg_namespace WHERE nspname = $1`, []interface{}{schemaName}, &foundSchema)
 
 	queries := []string{}
 	switch {
@@ -188,34 +187,6 @@ func resourcePostgreSQLSchemaDelete(db *DBConnection, d *schema.ResourceData) er
 	return nil
 }
 
-func resourcePostgreSQLSchemaExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
-	database, schemaName, err := getDBSchemaName(d, db.client.databaseName)
-	if err != nil {
-		return false, err
-	}
-
-	// Check if the database exists
-	exists, err := dbExists(db, database)
-	if err != nil || !exists {
-		return false, err
-	}
-
-	dbConn, err := connectToDatabase(db, database)
-	if err != nil {
-		return false, err
-	}
-
-	err = dbConn.QueryRow("SELECT n.nspname FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", schemaName).Scan(&schemaName)
-	switch {
-	case err == sql.ErrNoRows:
-		return false, nil
-	case err != nil:
-		return false, fmt.Errorf("Error reading schema: %w", err)
-	}
-
-	return true, nil
-}
-
 func resourcePostgreSQLSchemaRead(db *DBConnection, d *schema.ResourceData) error {
 	return resourcePostgreSQLSchemaReadImpl(db, d)
 }
@@ -226,13 +197,24 @@ func resourcePostgreSQLSchemaReadImpl(db *DBConnection, d *schema.ResourceData)
 		return err
 	}
 
+	// The schema is gone with its database, which can't be connected to.
+	exists, err := dbExists(db, database)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("[WARN] PostgreSQL database (%s) of schema %s not found", database, schemaName)
+		d.SetId("")
+		return nil
+	}
+
 	dbConn, err := connectToDatabase(db, database)
 	if err != nil {
 		return err
 	}
 
 	var schemaOwner string
-	err = dbConn.QueryRow("SELECT pg_catalog.pg_get_userbyid(n.nspowner) FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", schemaName).Scan(&schemaOwner)
+	err = queryRowScan(dbConn, "SELECT pg_catalog.pg_get_userbyid(n.nspowner) FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", []interface{}{schemaName}, &schemaOwner)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL schema (%s) not found in database %s", schemaName, database)
@@ -0,0 +1,124 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// SQLSTATE codes CockroachDB returns when a statement can safely be retried:
+	// serialization failures (restart transaction) and ambiguous commits.
+	pqErrorCodeSerializationFailure = "40001"
+	pqErrorCodeStatementCompletion  = "40003"
+
+	defaultProviderMaxRetries = 5
+
+	retryBaseDelay = 100 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// isRetryableError returns true if err is a CockroachDB error which is expected to
+// succeed when the statement is run again.
+func isRetryableError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqErrorCodeSerializationFailure || pqErr.Code == pqErrorCodeStatementCompletion
+}
+
+// isSerializationFailure returns true if err is a serialization failure, meaning the statement
+// wasn't applied. Unlike an ambiguous commit, it can be retried for statements which aren't idempotent.
+func isSerializationFailure(err error) bool {
+	return isPqErrorCode(err, pqErrorCodeSerializationFailure)
+}
+
+// retryDelay returns the jittered exponential backoff before the given retry attempt (starting at 1).
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// withRetry runs fn, running it again with a jittered backoff while it fails with
+// an error for which retryable returns true, up to the max_retries provider setting.
+func (db *DBConnection) withRetry(statement string, retryable func(error) bool, fn func() error) error {
+	maxRetries := 0
+	if db.client != nil {
+		maxRetries = db.client.config.MaxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) || attempt > maxRetries {
+			return err
+		}
+
+		delay := retryDelay(attempt)
+		log.Printf("[WARN] retrying statement in %s (attempt %d/%d) after error %v: %s", delay, attempt, maxRetries, err, statement)
+		select {
+		case <-db.context().Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Exec runs an autocommit statement, retrying serialization errors. Ambiguous commits are
+// not retried as the statement may have been applied, and running DDL again may fail.
+func (db *DBConnection) Exec(query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := db.withRetry(query, isSerializationFailure, func() error {
+		var err error
+		result, err = db.DB.ExecContext(db.context(), query, args...)
+		return err
+	})
+	return result, err
+}
+
+// Query runs an autocommit query, retrying serialization and ambiguous commit errors.
+// Errors returned while iterating over the rows are not retried.
+func (db *DBConnection) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := db.withRetry(query, isRetryableError, func() error {
+		var err error
+		rows, err = db.DB.QueryContext(db.context(), query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow runs a single-row query with the context of the operation. Its errors are
+// only returned by Scan, so they are not retried: reads should use queryRowScan.
+func (db *DBConnection) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(db.context(), query, args...)
+}
+
+// queryRowScan runs a single-row query and scans it into dest, retrying serialization and
+// ambiguous commit errors. sql.ErrNoRows is returned as is.
+func (db *DBConnection) queryRowScan(query string, args []interface{}, dest ...interface{}) error {
+	return db.withRetry(query, isRetryableError, func() error {
+		return db.DB.QueryRowContext(db.context(), query, args...).Scan(dest...)
+	})
+}
+
+// queryRowScan runs a single-row read on db, retried when db is a connection. Inside a
+// transaction, the whole transaction is retried by withTransaction instead.
+func queryRowScan(db QueryAble, query string, args []interface{}, dest ...interface{}) error {
+	if conn, ok := db.(*DBConnection); ok {
+		return conn.queryRowScan(query, args, dest...)
+	}
+	return db.QueryRow(query, args...).Scan(dest...)
+}
+
+// Begin starts a transaction which is rolled back if the context of the operation is cancelled.
+func (db *DBConnection) Begin() (*sql.Tx, error) {
+	return db.DB.BeginTx(db.context(), nil)
+}
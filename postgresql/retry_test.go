@@ -0,0 +1,82 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{err: nil, expected: false},
+		{err: errors.New("restart transaction"), expected: false},
+		{err: &pq.Error{Code: "40001"}, expected: true},
+		{err: &pq.Error{Code: "40003"}, expected: true},
+		{err: fmt.Errorf("could not execute grant query: %w", &pq.Error{Code: "40001"}), expected: true},
+		{err: &pq.Error{Code: "42P01"}, expected: false},
+	}
+
+	for _, c := range cases {
+		if out := isRetryableError(c.err); out != c.expected {
+			t.Fatalf("Error matching output and expected for %v: %#v vs %#v", c.err, out, c.expected)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt < 100; attempt++ {
+		delay := retryDelay(attempt)
+		if delay <= 0 || delay > retryMaxDelay {
+			t.Fatalf("retry delay of attempt %d out of bounds: %s", attempt, delay)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	db := &DBConnection{client: &Client{config: Config{MaxRetries: 2}}}
+
+	calls := 0
+	err := db.withRetry("SELECT 1", isRetryableError, func() error {
+		calls++
+		if calls < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got %d calls and error %v", calls, err)
+	}
+
+	calls = 0
+	err = db.withRetry("SELECT 1", isRetryableError, func() error {
+		calls++
+		return &pq.Error{Code: "40001"}
+	})
+	if !isRetryableError(err) || calls != 3 {
+		t.Fatalf("expected retryable error after 3 calls, got %d calls and error %v", calls, err)
+	}
+
+	calls = 0
+	err = db.withRetry("SELECT 1", isRetryableError, func() error {
+		calls++
+		return &pq.Error{Code: "42P01"}
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("expected non-retryable error after 1 call, got %d calls and error %v", calls, err)
+	}
+
+	// Ambiguous commits aren't retried for statements which aren't idempotent.
+	calls = 0
+	err = db.withRetry("CREATE ROLE r", isSerializationFailure, func() error {
+		calls++
+		return &pq.Error{Code: "40003"}
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("expected ambiguous commit error after 1 call, got %d calls and error %v", calls, err)
+	}
+}
@@ -0,0 +1,217 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+)
+
+const defaultSSHPort = "22"
+
+var (
+	sshTunnelsLock sync.Mutex
+	sshTunnels     = make(map[*SSHTunnelConfig]struct{})
+)
+
+// SSHTunnelConfig - SSH bastion the connections are tunneled through. A single SSH
+// connection is opened on first use and shared by every connection of the provider
+// configuration; it is opened again if the bastion closes it.
+type SSHTunnelConfig struct {
+	Host                 string
+	User                 string
+	PrivateKey           string
+	PrivateKeyFile       string
+	PrivateKeyPassphrase string
+	UseAgent             bool
+	KnownHostsFile       string
+
+	lock      sync.Mutex
+	client    *ssh.Client
+	agentConn net.Conn
+}
+
+func (s *SSHTunnelConfig) address() string {
+	if _, _, err := net.SplitHostPort(s.Host); err == nil {
+		return s.Host
+	}
+	return net.JoinHostPort(s.Host, defaultSSHPort)
+}
+
+// dialContext opens a connection to address through the bastion, connecting to the
+// bastion with forward if the tunnel isn't open yet.
+func (s *SSHTunnelConfig) dialContext(ctx context.Context, network, address string, forward proxy.Dialer) (net.Conn, error) {
+	client, err := s.connect(ctx, forward)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s through SSH bastion %s: %w", address, s.address(), err)
+	}
+	return conn, nil
+}
+
+func (s *SSHTunnelConfig) connect(ctx context.Context, forward proxy.Dialer) (*ssh.Client, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	config, err := s.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	address := s.address()
+	conn, err := dialContext(ctx, forward, "tcp", address)
+	if err != nil {
+		s.closeAgent()
+		return nil, fmt.Errorf("could not connect to SSH bastion %s: %w", address, err)
+	}
+
+	// Bound the handshake by the deadline of the connection which needs the tunnel.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		conn.Close()
+		s.closeAgent()
+		return nil, fmt.Errorf("could not open SSH session to bastion %s: %w", address, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+	s.client = client
+	log.Printf("[DEBUG] opened SSH tunnel through bastion %s as %s", address, s.User)
+
+	sshTunnelsLock.Lock()
+	sshTunnels[s] = struct{}{}
+	sshTunnelsLock.Unlock()
+
+	go func() {
+		err := client.Wait()
+		log.Printf("[DEBUG] SSH tunnel through bastion %s closed: %v", address, err)
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if s.client == client {
+			s.client = nil
+			s.closeAgent()
+		}
+	}()
+
+	return client, nil
+}
+
+func (s *SSHTunnelConfig) clientConfig() (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+
+	if s.PrivateKey != "" || s.PrivateKeyFile != "" {
+		key := []byte(s.PrivateKey)
+		if s.PrivateKeyFile != "" {
+			var err error
+			if key, err = os.ReadFile(s.PrivateKeyFile); err != nil {
+				return nil, fmt.Errorf("could not read SSH private key: %w", err)
+			}
+		}
+
+		var signer ssh.Signer
+		var err error
+		if s.PrivateKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(s.PrivateKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse SSH private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if s.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, errors.New("could not connect to the SSH agent: SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to the SSH agent: %w", err)
+		}
+		s.agentConn = conn
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	if len(auth) == 0 {
+		return nil, errors.New("SSH tunnel requires a private key or the SSH agent")
+	}
+
+	knownHostsFile := s.KnownHostsFile
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			s.closeAgent()
+			return nil, fmt.Errorf("could not find the known_hosts file: %w", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		s.closeAgent()
+		return nil, fmt.Errorf("could not read the known_hosts file: %w", err)
+	}
+
+	return &ssh.ClientConfig{
+		User:            s.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+func (s *SSHTunnelConfig) closeAgent() {
+	if s.agentConn != nil {
+		s.agentConn.Close()
+		s.agentConn = nil
+	}
+}
+
+// close closes the SSH connection, and every connection tunneled through it.
+func (s *SSHTunnelConfig) close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	s.closeAgent()
+	return err
+}
+
+// closeSSHTunnels closes the SSH tunnels opened by every provider configuration.
+func closeSSHTunnels() {
+	sshTunnelsLock.Lock()
+	defer sshTunnelsLock.Unlock()
+
+	for tunnel := range sshTunnels {
+		if err := tunnel.close(); err != nil {
+			log.Printf("[WARN] could not close SSH tunnel through bastion %s: %v", tunnel.address(), err)
+		}
+		delete(sshTunnels, tunnel)
+	}
+}
@@ -0,0 +1,170 @@
+package postgresql
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startTestSSHBastion starts an SSH server forwarding direct-tcpip channels, which
+// accepts the client key, and returns its address and the number of SSH connections.
+func startTestSSHBastion(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) (string, *int32) {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "bastion" && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	var connections int32
+	address := startTestListener(t, func(conn net.Conn) {
+		_, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			conn.Close()
+			return
+		}
+		atomic.AddInt32(&connections, 1)
+		go ssh.DiscardRequests(reqs)
+
+		for newChannel := range chans {
+			if newChannel.ChannelType() != "direct-tcpip" {
+				_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+				continue
+			}
+			var payload struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, fmt.Sprint(payload.Port)))
+			if err != nil {
+				_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				target.Close()
+				continue
+			}
+			go ssh.DiscardRequests(requests)
+			go func() {
+				defer channel.Close()
+				defer target.Close()
+				go func() { _, _ = io.Copy(target, channel) }()
+				_, _ = io.Copy(channel, target)
+			}()
+		}
+	})
+	return address, &connections
+}
+
+func TestSSHTunnel(t *testing.T) {
+	_, hostPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, err := ssh.NewSignerFromKey(hostPrivateKey)
+	if err != nil {
+		t.Fatalf("could not create host key: %v", err)
+	}
+	clientPublicKey, clientPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	clientSSHKey, err := ssh.NewPublicKey(clientPublicKey)
+	if err != nil {
+		t.Fatalf("could not create client key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPrivateKey, "")
+	if err != nil {
+		t.Fatalf("could not encode client key: %v", err)
+	}
+
+	bastion, connections := startTestSSHBastion(t, hostKey, clientSSHKey)
+	target := startTestListener(t, func(conn net.Conn) {
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	})
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(bastion)}, hostKey.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("could not write known_hosts: %v", err)
+	}
+
+	tunnel := &SSHTunnelConfig{
+		Host:           bastion,
+		User:           "bastion",
+		PrivateKey:     string(pem.EncodeToMemory(block)),
+		KnownHostsFile: knownHosts,
+	}
+	driver := proxyDriver{sshTunnel: tunnel}
+
+	for i := 0; i < 3; i++ {
+		conn, err := driver.DialTimeout("tcp", target, 5*time.Second)
+		if err != nil {
+			t.Fatalf("could not dial through the SSH tunnel: %v", err)
+		}
+		message := fmt.Sprintf("ping %d", i)
+		if _, err := io.WriteString(conn, message); err != nil {
+			t.Fatalf("could not write through the SSH tunnel: %v", err)
+		}
+		reply := make([]byte, len(message))
+		if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != message {
+			t.Fatalf("Error matching output and expected: %#v vs %#v (%v)", string(reply), message, err)
+		}
+		conn.Close()
+	}
+	if n := atomic.LoadInt32(connections); n != 1 {
+		t.Fatalf("expected every connection to share a single SSH connection, got %d", n)
+	}
+
+	// Closing the tunnels opens a new SSH connection on the next dial.
+	closeSSHTunnels()
+	conn, err := driver.DialContext(context.Background(), "tcp", target)
+	if err != nil {
+		t.Fatalf("could not dial through the reopened SSH tunnel: %v", err)
+	}
+	conn.Close()
+	if n := atomic.LoadInt32(connections); n != 2 {
+		t.Fatalf("expected the SSH tunnel to be reopened, got %d connections", n)
+	}
+	closeSSHTunnels()
+
+	// Bastions whose host key isn't known are refused.
+	_, otherPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewSignerFromKey(otherPrivateKey)
+	line = knownhosts.Line([]string{knownhosts.Normalize(bastion)}, otherKey.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("could not write known_hosts: %v", err)
+	}
+	tunnel = &SSHTunnelConfig{Host: bastion, User: "bastion", PrivateKey: tunnel.PrivateKey, KnownHostsFile: knownHosts}
+	_, err = proxyDriver{sshTunnel: tunnel}.Dial("tcp", target)
+	if err == nil || !strings.Contains(err.Error(), "key mismatch") {
+		t.Fatalf("expected a host key mismatch, got %v", err)
+	}
+}
+
+func TestSSHTunnelConfigAddress(t *testing.T) {
+	if address := (&SSHTunnelConfig{Host: "bastion.example.com"}).address(); address != "bastion.example.com:22" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", address, "bastion.example.com:22")
+	}
+	if address := (&SSHTunnelConfig{Host: "bastion.example.com:2222"}).address(); address != "bastion.example.com:2222" {
+		t.Fatalf("Error matching output and expected: %#v vs %#v", address, "bastion.example.com:2222")
+	}
+}